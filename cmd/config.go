@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tittuvarghese/ss-go-product-service/constants"
+	appconfig "github.com/tittuvarghese/ss-go-product-service/core/config"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `usage: product-service config <command> [flags]
+
+commands:
+  print     print the resolved configuration with secrets masked
+  validate  check the resolved configuration and report every problem`
+
+// runConfigCommand implements the config subcommands and returns the exit code.
+func runConfigCommand(args []string, lookup func(string) string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	command := args[0]
+	if command != "print" && command != "validate" {
+		fmt.Fprintf(os.Stderr, "unknown config command %q\n%s\n", command, configUsage)
+		return 2
+	}
+
+	cfg, _, err := appconfig.Load(constants.ModuleName+" config "+command, args[1:], lookup)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch command {
+	case "print":
+		out, err := yaml.Marshal(cfg.Masked())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		os.Stdout.Write(out)
+	case "validate":
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "configuration is invalid:\n%v\n", err)
+			return 1
+		}
+		fmt.Println("configuration is valid")
+	}
+	return 0
+}
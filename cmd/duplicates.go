@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tittuvarghese/ss-go-product-service/constants"
+	appconfig "github.com/tittuvarghese/ss-go-product-service/core/config"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+)
+
+const duplicatesUsage = `usage: product-service duplicates [flags] [category]
+
+Clusters the listed products of the catalog, or of one category, into groups
+of near-duplicates across sellers, using the duplicates settings.`
+
+// runDuplicatesCommand prints the clusters of duplicate products and returns
+// the exit code.
+func runDuplicatesCommand(args []string, lookup func(string) string) int {
+	cfg, rest, err := appconfig.Load(constants.ModuleName+" duplicates", args, lookup)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(rest) > 1 {
+		fmt.Fprintln(os.Stderr, duplicatesUsage)
+		return 2
+	}
+	category := ""
+	if len(rest) == 1 {
+		category = rest[0]
+	}
+
+	dbInstance, err := database.NewRelationalDatabase(cfg.Database.Url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error initialising relational db:", err)
+		return 1
+	}
+	if err := dbInstance.Open(); err != nil {
+		fmt.Fprintln(os.Stderr, "error opening relational db:", err)
+		return 1
+	}
+	defer dbInstance.Close()
+
+	catalog, err := service.GetCatalog(context.Background(), category, dbInstance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	clusters := cfg.Duplicates.Detector().Cluster(catalog)
+	if len(clusters) == 0 {
+		fmt.Printf("no duplicates among %d products\n", len(catalog))
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tPRODUCT\tSELLER\tCATEGORY\tSTATUS\tSIMILARITY\tNAME")
+	for i, cluster := range clusters {
+		for j, product := range cluster.Products {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%.2f\t%s\n", i+1, product.ID, product.SellerId, product.Category, product.Status, cluster.Best(j), product.Name)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tPRODUCT\tOTHER PRODUCT\tSIMILARITY\tDUPLICATE")
+	for i, cluster := range clusters {
+		for _, pair := range cluster.Pairs {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%.2f\t%t\n", i+1, cluster.Products[pair.A].ID, cluster.Products[pair.B].ID, pair.Similarity, pair.Duplicate)
+		}
+	}
+	w.Flush()
+	return 0
+}
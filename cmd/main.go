@@ -1,43 +1,380 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	// Time zones of the delivery configuration must resolve without a
+	// system time zone database
+	_ "time/tzdata"
+
+	"github.com/redis/go-redis/v9"
 	"github.com/tittuvarghese/ss-go-core/config"
 	"github.com/tittuvarghese/ss-go-core/logger"
 	"github.com/tittuvarghese/ss-go-product-service/constants"
+	"github.com/tittuvarghese/ss-go-product-service/core/cache"
+	appconfig "github.com/tittuvarghese/ss-go-product-service/core/config"
 	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/events"
 	"github.com/tittuvarghese/ss-go-product-service/core/handler"
+	"github.com/tittuvarghese/ss-go-product-service/core/images"
+	"github.com/tittuvarghese/ss-go-product-service/core/lifecycle"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/metrics"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/core/ratelimit"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"github.com/tittuvarghese/ss-go-product-service/core/tracing"
 	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 )
 
-func main() {
-	log := logger.NewLogger(constants.ModuleName)
-	log.Info("Initialising Customer Service Module")
+var log = logger.NewLogger(constants.ModuleName)
 
+func main() {
 	// Config Management
 	configManager := config.NewConfigManager(config.DEFAULT_CONFIG_PATH)
 	configManager.Enable()
+	lookup := func(key string) string {
+		if value := configManager.GetString(key); value != "" {
+			return value
+		}
+		return os.Getenv(key)
+	}
 
-	// DB Handling
-	dbConn := configManager.GetString(constants.DatabaseUrlEnvName)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			os.Exit(runConfigCommand(args[1:], lookup))
+		case "migrate":
+			os.Exit(runMigrateCommand(args[1:], lookup))
+		case "duplicates":
+			os.Exit(runDuplicatesCommand(args[1:], lookup))
+		}
+	}
+
+	cfg, _, err := appconfig.Load(constants.ModuleName, args, lookup)
+	if err != nil {
+		log.Error("Error loading configuration", err)
+		os.Exit(2)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Error("Invalid configuration", err)
+		os.Exit(2)
+	}
+
+	serve(cfg)
+}
+
+func serve(cfg appconfig.Config) {
+	log.Info("Initialising Customer Service Module")
 
-	log.Info("DB Connection String " + dbConn)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	dbInstance, err := database.NewRelationalDatabase(dbConn)
+	logging.SetBase(logging.New(os.Stdout, logging.ParseLevel(cfg.Logging.Level)))
+
+	// Components registered here are stopped in reverse order on shutdown
+	components := lifecycle.NewManager()
+
+	// Tracing
+	shutdownTracing, err := tracing.Init(ctx, tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Error("Error initialising tracing", err)
+	} else {
+		components.Register("tracing", shutdownTracing)
+	}
+
+	// DB Handling
+	log.Info("DB Connection String " + appconfig.MaskDSN(cfg.Database.Url))
+
+	dbInstance, err := database.NewRelationalDatabase(cfg.Database.Url)
 	if err != nil {
 		log.Error("Error initialising relational db", err)
+		os.Exit(1)
 	}
+	log.Info("Using " + dbInstance.Dialect + " database")
 
-	err = dbInstance.Instance.Open()
+	err = dbInstance.Open()
 	if err != nil {
 		log.Error("Error opening relational db", err)
+		os.Exit(1)
+	}
+	components.Register("relational db", func(ctx context.Context) error {
+		return dbInstance.Close()
+	})
+
+	for _, replicaUrl := range cfg.Database.ReplicaUrls {
+		log.Info("Read replica " + appconfig.MaskDSN(replicaUrl))
+		if err := dbInstance.AddReplica(replicaUrl); err != nil {
+			log.Error("Error opening read replica", err)
+			os.Exit(1)
+		}
+	}
+	dbInstance.StartHealthChecks(cfg.Database.ReplicaHealthCheckInterval, cfg.Database.ReplicaFailureThreshold)
+
+	err = dbInstance.ConfigurePool(database.PoolOptions{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	})
+	if err != nil {
+		log.Error("Error configuring relational db pool", err)
+	}
+
+	// Replicas starting together wait on the migration lock, so only one of
+	// them applies the pending migrations
+	if cfg.Features.MigrateOnStartup {
+		migrator, err := newMigrator(dbInstance)
+		if err == nil {
+			_, err = migrator.Up(ctx, 0)
+		}
+		if err != nil {
+			log.Error("Error migrating the database", err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Pricing.FxRatesFile != "" {
+		rates, err := pricing.ReadRatesFile(cfg.Pricing.FxRatesFile)
+		if err == nil {
+			err = service.SetFxRates(ctx, rates, dbInstance)
+		}
+		if err != nil {
+			log.Error("Error loading exchange rates", err)
+			os.Exit(1)
+		}
+		log.Info("Loaded " + strconv.Itoa(len(rates)) + " exchange rates from " + cfg.Pricing.FxRatesFile)
+	}
+
+	moderationRules, err := cfg.Moderation.Rules.Engine()
+	if err != nil {
+		log.Error("Error compiling moderation rules", err)
+		os.Exit(1)
 	}
 
-	err = dbInstance.Instance.AutoMigrate(models.Product{})
+	blobs, err := cfg.Images.BlobStore()
 	if err != nil {
-		log.Error("Error performing auto migration for db", err)
+		log.Error("Error opening the image store", err)
+		os.Exit(1)
+	}
+
+	estimator, err := cfg.Delivery.Estimator()
+	if err != nil {
+		log.Error("Error loading delivery calendars", err)
+		os.Exit(1)
+	}
+
+	schedules, err := pricing.NewSchedules(ctx, func(ctx context.Context) ([]models.PriceSchedule, error) {
+		return service.GetPriceSchedules(ctx, time.Now(), dbInstance)
+	}, cfg.Pricing.ScheduleRefreshInterval)
+	if err != nil {
+		log.Error("Error loading price schedules", err)
+		os.Exit(1)
+	}
+	components.Register("price schedules", schedules.Close)
+
+	renditions := images.NewPipeline(images.PipelineOptions{
+		Workers:       cfg.Images.Renditions.Workers,
+		QueueSize:     cfg.Images.Renditions.QueueSize,
+		SweepInterval: cfg.Images.Renditions.SweepInterval,
+		Specs:         cfg.Images.Renditions.Specs(),
+		Quality:       cfg.Images.Renditions.JpegQuality,
+		Blobs:         blobs,
+		Pending: func(ctx context.Context, limit int) ([]models.ProductImage, error) {
+			return service.GetPendingImages(database.WithPrimary(ctx), limit, dbInstance)
+		},
+		Save: func(ctx context.Context, image models.ProductImage, renditions []models.ProductImageRendition, status string) error {
+			err := service.SaveImageRenditions(ctx, image.ID.String(), renditions, status, dbInstance)
+			if errors.Is(err, service.ErrImageNotFound) {
+				return images.ErrImageDeleted
+			}
+			return err
+		},
+	})
+	components.Register("image renditions", renditions.Close)
+
+	var publisher events.Publisher = events.LogPublisher{}
+	if cfg.Events.Backend == appconfig.EventsBackendRedis {
+		redisPublisher := events.NewRedisPublisher(redis.NewClient(&redis.Options{
+			Addr:     cfg.Events.RedisAddr,
+			Password: cfg.Events.RedisPassword,
+			DB:       cfg.Events.RedisDB,
+		}), cfg.Events.Stream, int64(cfg.Events.MaxLen))
+		components.Register("redis events", redisPublisher.Close)
+		publisher = redisPublisher
+	}
+	relay := events.NewRelay(events.RelayOptions{
+		Interval:  cfg.Events.RelayInterval,
+		BatchSize: cfg.Events.BatchSize,
+		Publisher: publisher,
+		Load: func(ctx context.Context, limit int) ([]events.Event, error) {
+			return service.GetOutboxEvents(database.WithPrimary(ctx), limit, dbInstance)
+		},
+		Delete: func(ctx context.Context, ids []string) error {
+			return service.DeleteOutboxEvents(ctx, ids, dbInstance)
+		},
+	})
+	components.Register("event relay", relay.Close)
+
+	// Metrics
+	if cfg.Metrics.Enabled {
+		if sqlDB, err := dbInstance.SqlDB(); err != nil {
+			log.Error("Error reading relational db pool for metrics", err)
+		} else if err := metrics.RegisterDBStats(sqlDB, constants.ModuleName); err != nil {
+			log.Error("Error registering db pool metrics", err)
+		}
+		if pools, err := dbInstance.ReplicaPools(); err != nil {
+			log.Error("Error reading read replica pools for metrics", err)
+		} else {
+			for name, pool := range pools {
+				if err := metrics.RegisterDBStats(pool, constants.ModuleName+"-"+name); err != nil {
+					log.Error("Error registering read replica pool metrics", err)
+				}
+			}
+		}
+		err = metrics.RegisterInventory(func(ctx context.Context) (metrics.InventoryStats, error) {
+			return service.GetInventoryStats(ctx, dbInstance)
+		})
+		if err != nil {
+			log.Error("Error registering inventory metrics", err)
+		}
+		metricsServer := metrics.NewServer(strconv.Itoa(cfg.Metrics.Port))
+		go metrics.Serve(metricsServer)
+		components.Register("metrics server", func(ctx context.Context) error {
+			return metricsServer.Shutdown(ctx)
+		})
 	}
 
-	server := handler.NewGrpcServer()
+	interceptors := []grpc.UnaryServerInterceptor{
+		logging.UnaryServerInterceptor(),
+		metrics.UnaryServerInterceptor(),
+		security.UnaryServerInterceptor(cfg.TLS.IdentityMap()),
+		database.UnaryServerInterceptor(cfg.Database.ReadYourWritesWindow),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		logging.StreamServerInterceptor(),
+		metrics.StreamServerInterceptor(),
+		security.StreamServerInterceptor(cfg.TLS.IdentityMap()),
+		database.StreamServerInterceptor(cfg.Database.ReadYourWritesWindow),
+	}
+	sellerAgents := security.NewRole("seller agent", cfg.Sellers.AgentIdentities, cfg.Sellers.AllowAllAgents)
+	if cfg.RateLimit.Enabled {
+		limits := ratelimit.Options{
+			Default:      ratelimit.Limit{Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst},
+			Methods:      map[string]ratelimit.Limit{},
+			SellerAgents: sellerAgents,
+		}
+		for _, method := range cfg.RateLimit.Methods {
+			limits.Methods[method.Method] = ratelimit.Limit{Rate: method.Rate, Burst: method.Burst}
+		}
+		limiter := ratelimit.NewMemoryBackend(cfg.RateLimit.IdleTimeout)
+		components.Register("rate limiter", limiter.Close)
+		interceptors = append(interceptors, ratelimit.UnaryServerInterceptor(limiter, limits))
+		streamInterceptors = append(streamInterceptors, ratelimit.StreamServerInterceptor(limiter, limits))
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.ConnectionTimeout(cfg.Server.ConnectionTimeout),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if cfg.TLS.Plaintext {
+		log.Info("TLS is disabled, serving plaintext gRPC for local development")
+	} else {
+		creds, err := security.NewServerCredentials(security.TLSOptions{
+			CertFile:       cfg.TLS.CertFile,
+			KeyFile:        cfg.TLS.KeyFile,
+			ClientCAFile:   cfg.TLS.ClientCAFile,
+			ClientAuth:     cfg.TLS.ClientAuth,
+			Identities:     cfg.TLS.IdentityMap(),
+			ReloadInterval: cfg.TLS.ReloadInterval,
+		})
+		if err != nil {
+			log.Error("Error loading TLS credentials", err)
+			os.Exit(1)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := handler.NewGrpcServer(opts...)
 	server.RdbInstance = dbInstance
-	server.Run(constants.GrpcServerPort)
+	if cfg.Cache.Enabled {
+		cacheOpts := cache.ProductCacheOptions{
+			Size:        cfg.Cache.Size,
+			TTL:         cfg.Cache.TTL,
+			NegativeTTL: cfg.Cache.NegativeTTL,
+		}
+		if len(cfg.Database.ReplicaUrls) > 0 {
+			cacheOpts.ReplicationDelay = cfg.Database.ReadYourWritesWindow
+		}
+		if cfg.Cache.RedisAddr != "" {
+			remote := cache.NewRedisRemote(redis.NewClient(&redis.Options{
+				Addr:     cfg.Cache.RedisAddr,
+				Password: cfg.Cache.RedisPassword,
+				DB:       cfg.Cache.RedisDB,
+			}), constants.ModuleName+":")
+			components.Register("redis cache", remote.Close)
+			cacheOpts.Remote = remote
+		}
+		server.ProductCache = cache.NewProductCache(cacheOpts)
+	}
+	server.EnableReflection = cfg.Features.Reflection
+	server.Rounding = cfg.Pricing.RoundingRules()
+	server.Schedules = schedules
+	server.Admins = security.NewRole("admin", cfg.Admin.Identities, cfg.Admin.AllowAll)
+	server.SellerAgents = sellerAgents
+	server.Moderators = security.NewRole("moderator", cfg.Moderation.Identities, cfg.Moderation.AllowAll)
+	server.ModerationRules = moderationRules
+	server.PurchaseVerifiers = security.NewRole("purchase verifier", cfg.Reviews.VerifierIdentities, cfg.Reviews.AllowAllVerifiers)
+	server.ReviewApproval = cfg.Reviews.RequireApproval
+	server.QuestionApproval = cfg.Questions.RequireApproval
+	server.Duplicates = cfg.Duplicates.Detector()
+	server.DuplicateMode = cfg.Duplicates.Mode
+	server.Shipping = cfg.Shipping.ShippingRates()
+	server.Delivery = estimator
+	server.Blobs = blobs
+	server.ImageLimits = cfg.Images.Limits()
+	server.Renditions = renditions
+	server.Events = relay
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Run(strconv.Itoa(cfg.Server.GrpcPort))
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("Shutdown signal received, draining in-flight requests")
+	case err := <-serveErr:
+		log.Error("GRPC server stopped unexpectedly", err)
+	}
+	stop()
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.Server.ShutdownDrainTimeout)
+	defer cancelDrain()
+	if err := server.Shutdown(drainCtx); err != nil {
+		log.Error("GRPC server did not drain before the deadline, forced stop", err)
+	}
+
+	// No RPC can reach the workers or the database anymore, flush and release them
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.Server.ShutdownDrainTimeout)
+	defer cancelFlush()
+	if err := components.Shutdown(flushCtx); err != nil {
+		log.Error("Error during shutdown", err)
+	}
+	log.Info("Product service stopped")
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/tittuvarghese/ss-go-product-service/constants"
+	appconfig "github.com/tittuvarghese/ss-go-product-service/core/config"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/migrate"
+	"github.com/tittuvarghese/ss-go-product-service/migrations"
+)
+
+const migrateUsage = `usage: product-service migrate <command> [flags] [steps]
+
+commands:
+  up      apply pending migrations, all of them unless steps is given
+  down    revert the last applied migration, or the last steps migrations
+  status  list every migration and whether it has been applied`
+
+// runMigrateCommand implements the migrate subcommands and returns the exit code.
+func runMigrateCommand(args []string, lookup func(string) string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command := args[0]
+	if command != "up" && command != "down" && command != "status" {
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n%s\n", command, migrateUsage)
+		return 2
+	}
+
+	cfg, rest, err := appconfig.Load(constants.ModuleName+" migrate "+command, args[1:], lookup)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	steps := 0
+	if command == "down" {
+		steps = 1
+	}
+	if len(rest) > 0 {
+		steps, err = strconv.Atoi(rest[0])
+		if err != nil || steps <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid number of steps %q\n", rest[0])
+			return 2
+		}
+	}
+
+	dbInstance, err := database.NewRelationalDatabase(cfg.Database.Url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error initialising relational db:", err)
+		return 1
+	}
+	if err := dbInstance.Open(); err != nil {
+		fmt.Fprintln(os.Stderr, "error opening relational db:", err)
+		return 1
+	}
+	defer dbInstance.Close()
+
+	migrator, err := newMigrator(dbInstance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		w.Flush()
+	}
+	return 0
+}
+
+func newMigrator(dbInstance *database.RelationalDatabase) (*migrate.Migrator, error) {
+	sqlDB, err := dbInstance.SqlDB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrations.Files, migrate.Options{Dialect: dbInstance.Dialect})
+}
@@ -2,9 +2,3 @@ package constants
 
 const AppName = "ecommerce-application"
 const ModuleName = "product-service"
-const GrpcServerPort = "8083"
-
-// Env Variables
-const (
-	DatabaseUrlEnvName = "DATABASE_URL"
-)
@@ -0,0 +1,46 @@
+// Package blob stores the binary content of the service, such as product
+// images, in a local directory or an S3 compatible object store.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores blobs under slash separated keys, e.g.
+// products/<product id>/<image id>.jpg.
+type BlobStore interface {
+	// Put stores content under key, replacing any previous blob.
+	Put(ctx context.Context, key string, content []byte, contentType string) error
+	// Get returns the blob stored under key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// no error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients download the blob from.
+	URL(key string) string
+}
+
+// checkKey rejects keys which could escape the directory or bucket prefix
+// of a store.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// joinURL appends key to the base URL.
+func joinURL(base string, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -0,0 +1,91 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "https://cdn.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "products/p1/i1.jpg"
+	if err := store.Put(ctx, key, []byte("first"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, key, []byte("second"), "image/jpeg"); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	content, err := store.Get(ctx, key)
+	if err != nil || string(content) != "second" {
+		t.Fatalf("Get = %q, %v, want the replaced content", content, err)
+	}
+	if url := store.URL(key); url != "https://cdn.example.com/products/p1/i1.jpg" {
+		t.Errorf("URL = %q", url)
+	}
+
+	// No temporary file is left next to the blob
+	entries, err := os.ReadDir(filepath.Join(store.Dir, "products", "p1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the blob", len(entries))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(root, "blobs"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file next to the store directory, which no key may reach
+	outside := filepath.Join(root, "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{
+		"",
+		"../secret",
+		"products/../../secret",
+		"/etc/passwd",
+		"products//i1.jpg",
+		"products/./i1.jpg",
+		"products/",
+		`..\secret`,
+	} {
+		if err := store.Put(ctx, key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid key", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want an invalid key", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded, want an invalid key", key)
+		}
+	}
+
+	content, err := os.ReadFile(outside)
+	if err != nil || string(content) != "secret" {
+		t.Errorf("file outside the store = %q, %v, want it untouched", content, err)
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestLRU returns an LRU whose clock only moves when the returned function
+// advances it.
+func newTestLRU(capacity int) (*LRU[string, int], func(time.Duration)) {
+	c := NewLRU[string, int](capacity)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestLRU(2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	// Reading a makes b the least recently used
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	c.Set("c", 3, time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if value, ok := c.Get(key); !ok || value != want {
+			t.Errorf("Get(%s) = %d, %t, want %d", key, value, ok, want)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d, want 2", c.Len())
+	}
+
+	// Replacing a value does not grow the cache
+	c.Set("a", 10, time.Minute)
+	if value, _ := c.Get("a"); value != 10 || c.Len() != 2 {
+		t.Errorf("after replacing a: Get = %d, Len = %d", value, c.Len())
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	c, advance := newTestLRU(10)
+	c.Set("short", 1, time.Second)
+	c.Set("long", 2, time.Hour)
+
+	advance(time.Second)
+	if _, ok := c.Get("short"); !ok {
+		t.Error("short expired at its TTL, want it kept until after")
+	}
+	advance(time.Millisecond)
+	if _, ok := c.Get("short"); ok {
+		t.Error("short did not expire")
+	}
+	if _, ok := c.Get("long"); !ok {
+		t.Error("long expired early")
+	}
+	// Expired entries are dropped when read
+	if c.Len() != 1 {
+		t.Errorf("Len = %d, want 1", c.Len())
+	}
+
+	// Setting again renews the TTL
+	c.Set("long", 3, time.Second)
+	advance(2 * time.Second)
+	if _, ok := c.Get("long"); ok {
+		t.Error("long kept its former TTL")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	c, _ := newTestLRU(10)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Delete("a", "missing")
+	if _, ok := c.Get("a"); ok {
+		t.Error("a not deleted")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("b deleted")
+	}
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/metrics"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"golang.org/x/sync/singleflight"
+)
+
+const productKeyPrefix = "product:"
+
+// ProductLoader reads the given products from the database. Products which
+// do not exist are left out of the returned map.
+type ProductLoader func(ctx context.Context, ids []string) (map[string]models.Product, error)
+
+type ProductCacheOptions struct {
+	// Size is the maximum number of products kept in process.
+	Size int
+	// TTL bounds how stale a cached product may get.
+	TTL time.Duration
+	// NegativeTTL is how long unknown product ids are remembered.
+	NegativeTTL time.Duration
+	// Remote is an optional cache shared between replicas.
+	Remote Remote
+	// ReplicationDelay repeats every invalidation after the given delay when
+	// positive. Misses are loaded from read replicas, which may still return
+	// the old product shortly after a write and so cache it again.
+	ReplicationDelay time.Duration
+}
+
+// productEntry is a cached lookup. Found is false for negative entries.
+type productEntry struct {
+	Found   bool           `json:"found"`
+	Product models.Product `json:"product"`
+}
+
+// ProductCache is a read-through cache in front of the product queries.
+// Concurrent misses for the same products are collapsed into a single query.
+// A nil *ProductCache is valid and always reads through to the loader.
+//
+// Every invalidation starts a new generation. Loads remember the generation
+// they started in and only cache their result when it did not change, so a
+// load which may have read a product before its mutation never caches it
+// after the invalidation.
+type ProductCache struct {
+	local  *LRU[string, productEntry]
+	remote Remote
+	group  singleflight.Group
+	opts   ProductCacheOptions
+
+	mu         sync.Mutex
+	generation uint64
+}
+
+func NewProductCache(opts ProductCacheOptions) *ProductCache {
+	return &ProductCache{
+		local:  NewLRU[string, productEntry](opts.Size),
+		remote: opts.Remote,
+		opts:   opts,
+	}
+}
+
+// Get returns a single product, and false when it does not exist.
+func (c *ProductCache) Get(ctx context.Context, id string, load ProductLoader) (models.Product, bool, error) {
+	products, err := c.GetMany(ctx, []string{id}, load)
+	if err != nil {
+		return models.Product{}, false, err
+	}
+	product, ok := products[id]
+	return product, ok, nil
+}
+
+// GetMany returns the products found for ids, keyed by id.
+func (c *ProductCache) GetMany(ctx context.Context, ids []string, load ProductLoader) (map[string]models.Product, error) {
+	if c == nil {
+		return load(ctx, ids)
+	}
+
+	generation := c.currentGeneration()
+	products := make(map[string]models.Product, len(ids))
+	var misses []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, ok := c.local.Get(id)
+		if !ok {
+			entry, ok = c.getRemote(ctx, id, generation)
+		}
+		if !ok {
+			metrics.RecordCacheLookup("product", "miss")
+			misses = append(misses, id)
+			continue
+		}
+		metrics.RecordCacheLookup("product", "hit")
+		if entry.Found {
+			products[id] = entry.Product
+		}
+	}
+	if len(misses) == 0 {
+		return products, nil
+	}
+
+	// Callers of a later generation do not join a load which may have read
+	// products before their invalidation
+	sort.Strings(misses)
+	key := strconv.FormatUint(generation, 10) + ":" + strings.Join(misses, ",")
+	loaded, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// The query is shared by every waiting caller, so it must not be
+		// cancelled when the caller which started it goes away
+		loadCtx := context.WithoutCancel(ctx)
+		found, err := load(loadCtx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range misses {
+			product, ok := found[id]
+			c.store(loadCtx, id, productEntry{Found: ok, Product: product}, generation)
+		}
+		return found, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for id, product := range loaded.(map[string]models.Product) {
+		products[id] = product
+	}
+	return products, nil
+}
+
+// Invalidate drops the given products from every cache layer. It must be
+// called after each mutation of a product.
+func (c *ProductCache) Invalidate(ctx context.Context, ids ...string) {
+	if c == nil || len(ids) == 0 {
+		return
+	}
+	c.invalidate(ctx, ids)
+	if c.opts.ReplicationDelay > 0 {
+		ctx = context.WithoutCancel(ctx)
+		time.AfterFunc(c.opts.ReplicationDelay, func() {
+			c.invalidate(ctx, ids)
+		})
+	}
+}
+
+func (c *ProductCache) invalidate(ctx context.Context, ids []string) {
+	c.mu.Lock()
+	c.generation++
+	c.local.Delete(ids...)
+	c.mu.Unlock()
+	if c.remote == nil {
+		return
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = productKeyPrefix + id
+	}
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		logging.FromContext(ctx).Error("failed to invalidate remote product cache", "product_ids", ids, "error", err)
+	}
+}
+
+func (c *ProductCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// setLocal caches the entry in process unless an invalidation happened since
+// generation, and reports whether it did.
+func (c *ProductCache) setLocal(id string, entry productEntry, generation uint64) bool {
+	ttl := c.opts.TTL
+	if !entry.Found {
+		ttl = c.opts.NegativeTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return false
+	}
+	c.local.Set(id, entry, ttl)
+	return true
+}
+
+// store caches a loaded entry in every layer, unless an invalidation happened
+// since generation.
+func (c *ProductCache) store(ctx context.Context, id string, entry productEntry, generation uint64) {
+	if !c.setLocal(id, entry, generation) || c.remote == nil {
+		return
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	ttl := c.opts.TTL
+	if !entry.Found {
+		ttl = c.opts.NegativeTTL
+	}
+	key := productKeyPrefix + id
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		logging.FromContext(ctx).Error("failed to populate remote product cache", "product_id", id, "error", err)
+		return
+	}
+	// An invalidation may have deleted the key before it was set
+	if c.currentGeneration() != generation {
+		if err := c.remote.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Error("failed to invalidate remote product cache", "product_ids", []string{id}, "error", err)
+		}
+	}
+}
+
+func (c *ProductCache) getRemote(ctx context.Context, id string, generation uint64) (productEntry, bool) {
+	var entry productEntry
+	if c.remote == nil {
+		return entry, false
+	}
+	value, ok, err := c.remote.Get(ctx, productKeyPrefix+id)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to read remote product cache", "product_id", id, "error", err)
+		return entry, false
+	}
+	if !ok || json.Unmarshal(value, &entry) != nil {
+		return entry, false
+	}
+	// Keep the entry in process as well to spare the next remote round trip
+	c.setLocal(id, entry, generation)
+	return entry, true
+}
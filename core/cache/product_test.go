@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// countingLoader loads the products of a fixed catalog and counts its calls.
+type countingLoader struct {
+	mu       sync.Mutex
+	products map[string]models.Product
+	calls    atomic.Int32
+	// release, when set, holds every load until it is closed
+	release chan struct{}
+}
+
+func newCountingLoader(names ...string) (*countingLoader, []string) {
+	loader := &countingLoader{products: map[string]models.Product{}}
+	ids := make([]string, len(names))
+	for i, name := range names {
+		id := uuid.New()
+		ids[i] = id.String()
+		loader.products[ids[i]] = models.Product{ID: id, Name: name}
+	}
+	return loader, ids
+}
+
+func (l *countingLoader) load(ctx context.Context, ids []string) (map[string]models.Product, error) {
+	l.calls.Add(1)
+	if l.release != nil {
+		<-l.release
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	found := make(map[string]models.Product, len(ids))
+	for _, id := range ids {
+		if product, ok := l.products[id]; ok {
+			found[id] = product
+		}
+	}
+	return found, nil
+}
+
+func (l *countingLoader) rename(id string, name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	product := l.products[id]
+	product.Name = name
+	l.products[id] = product
+}
+
+func TestProductCacheReadsThrough(t *testing.T) {
+	ctx := context.Background()
+	c := NewProductCache(ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	loader, ids := newCountingLoader("kettle", "toaster")
+
+	for i := 0; i < 2; i++ {
+		products, err := c.GetMany(ctx, []string{ids[0], ids[1], ids[0]}, loader.load)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(products) != 2 || products[ids[0]].Name != "kettle" || products[ids[1]].Name != "toaster" {
+			t.Fatalf("GetMany = %v", products)
+		}
+	}
+	if calls := loader.calls.Load(); calls != 1 {
+		t.Errorf("loader called %d times, want once", calls)
+	}
+
+	// A nil cache reads through every time
+	var none *ProductCache
+	if product, ok, err := none.Get(ctx, ids[0], loader.load); err != nil || !ok || product.Name != "kettle" {
+		t.Errorf("Get through a nil cache = %v, %t, %v", product, ok, err)
+	}
+	if calls := loader.calls.Load(); calls != 2 {
+		t.Errorf("loader called %d times, want twice", calls)
+	}
+}
+
+func TestProductCacheCollapsesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	c := NewProductCache(ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	loader, ids := newCountingLoader("kettle")
+	loader.release = make(chan struct{})
+
+	const callers = 8
+	var started, done sync.WaitGroup
+	started.Add(callers)
+	done.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer done.Done()
+			started.Done()
+			product, ok, err := c.Get(ctx, ids[0], loader.load)
+			if err != nil || !ok || product.Name != "kettle" {
+				t.Errorf("Get = %v, %t, %v", product, ok, err)
+			}
+		}()
+	}
+	started.Wait()
+	// Give every caller the time to join the pending load
+	time.Sleep(50 * time.Millisecond)
+	close(loader.release)
+	done.Wait()
+
+	if calls := loader.calls.Load(); calls != 1 {
+		t.Errorf("loader called %d times for %d concurrent callers, want once", calls, callers)
+	}
+}
+
+func TestProductCacheRemembersUnknownProducts(t *testing.T) {
+	ctx := context.Background()
+	c := NewProductCache(ProductCacheOptions{Size: 10, TTL: time.Hour, NegativeTTL: time.Second})
+	now := time.Now()
+	c.local.now = func() time.Time { return now }
+	loader, _ := newCountingLoader()
+	missing := uuid.NewString()
+
+	for i := 0; i < 2; i++ {
+		if _, ok, err := c.Get(ctx, missing, loader.load); ok || err != nil {
+			t.Fatalf("Get of an unknown product = %t, %v", ok, err)
+		}
+	}
+	if calls := loader.calls.Load(); calls != 1 {
+		t.Errorf("loader called %d times, want once", calls)
+	}
+
+	// Negative entries expire after NegativeTTL, not TTL
+	now = now.Add(2 * time.Second)
+	c.Get(ctx, missing, loader.load)
+	if calls := loader.calls.Load(); calls != 2 {
+		t.Errorf("loader called %d times after NegativeTTL, want twice", calls)
+	}
+}
+
+func TestProductCacheDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := NewProductCache(ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	failure := errors.New("database down")
+	failing := func(ctx context.Context, ids []string) (map[string]models.Product, error) {
+		return nil, failure
+	}
+	loader, ids := newCountingLoader("kettle")
+
+	if _, _, err := c.Get(ctx, ids[0], failing); !errors.Is(err, failure) {
+		t.Fatalf("Get = %v, want the load error", err)
+	}
+	if product, ok, err := c.Get(ctx, ids[0], loader.load); err != nil || !ok || product.Name != "kettle" {
+		t.Errorf("Get after a failed load = %v, %t, %v", product, ok, err)
+	}
+}
+
+func TestProductCacheSharesEntriesThroughTheRemote(t *testing.T) {
+	ctx := context.Background()
+	remote, server := newTestRedisRemote(t)
+	opts := ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Second, Remote: remote}
+	first, second := NewProductCache(opts), NewProductCache(opts)
+	loader, ids := newCountingLoader("kettle")
+	missing := uuid.NewString()
+
+	if _, err := first.GetMany(ctx, []string{ids[0], missing}, loader.load); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL("test:" + productKeyPrefix + missing); ttl != time.Second {
+		t.Errorf("TTL of the negative entry = %s, want NegativeTTL", ttl)
+	}
+
+	// Another replica is served from the remote cache
+	products, err := second.GetMany(ctx, []string{ids[0], missing}, loader.load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || products[ids[0]].Name != "kettle" {
+		t.Errorf("GetMany from the remote = %v", products)
+	}
+	if calls := loader.calls.Load(); calls != 1 {
+		t.Errorf("loader called %d times, want once", calls)
+	}
+
+	// Invalidating drops the product from the remote as well
+	loader.rename(ids[0], "electric kettle")
+	first.Invalidate(ctx, ids[0])
+	if server.Exists("test:" + productKeyPrefix + ids[0]) {
+		t.Error("product still in the remote cache after Invalidate")
+	}
+	if product, _, _ := first.Get(ctx, ids[0], loader.load); product.Name != "electric kettle" {
+		t.Errorf("Get after Invalidate = %q, want the new name", product.Name)
+	}
+}
+
+func TestProductCacheInvalidatesAgainAfterReplicationDelay(t *testing.T) {
+	ctx := context.Background()
+	c := NewProductCache(ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute, ReplicationDelay: 20 * time.Millisecond})
+	loader, ids := newCountingLoader("kettle")
+
+	c.Get(ctx, ids[0], loader.load)
+	loader.rename(ids[0], "electric kettle")
+	c.Invalidate(ctx, ids[0])
+	// A lagging replica still returns the old product, which is cached again
+	loader.rename(ids[0], "kettle")
+	c.Get(ctx, ids[0], loader.load)
+	loader.rename(ids[0], "electric kettle")
+
+	time.Sleep(100 * time.Millisecond)
+	if product, _, _ := c.Get(ctx, ids[0], loader.load); product.Name != "electric kettle" {
+		t.Errorf("Get after the replication delay = %q, want the new name", product.Name)
+	}
+}
+
+func TestProductCacheDiscardsLoadsStartedBeforeAnInvalidation(t *testing.T) {
+	ctx := context.Background()
+	remote, server := newTestRedisRemote(t)
+	for name, opts := range map[string]ProductCacheOptions{
+		"local":  {Size: 10, TTL: time.Minute, NegativeTTL: time.Minute},
+		"remote": {Size: 10, TTL: time.Minute, NegativeTTL: time.Minute, Remote: remote},
+	} {
+		c := NewProductCache(opts)
+		loader, ids := newCountingLoader("kettle")
+
+		// The first load reads the product, then stalls until the product
+		// was renamed and invalidated
+		read, release := make(chan struct{}), make(chan struct{})
+		var first atomic.Bool
+		stalling := func(ctx context.Context, ids []string) (map[string]models.Product, error) {
+			found, err := loader.load(ctx, ids)
+			if first.CompareAndSwap(false, true) {
+				close(read)
+				<-release
+			}
+			return found, err
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if product, _, err := c.Get(ctx, ids[0], stalling); err != nil || product.Name != "kettle" {
+				t.Errorf("%s: stalled Get = %q, %v, want the old name", name, product.Name, err)
+			}
+		}()
+		<-read
+		loader.rename(ids[0], "electric kettle")
+		c.Invalidate(ctx, ids[0])
+
+		// Callers after the invalidation do not wait for the stale load
+		fresh := make(chan models.Product)
+		go func() {
+			product, _, _ := c.Get(ctx, ids[0], stalling)
+			fresh <- product
+		}()
+		select {
+		case product := <-fresh:
+			if product.Name != "electric kettle" {
+				t.Errorf("%s: Get during the stale load = %q, want the new name", name, product.Name)
+			}
+		case <-time.After(5 * time.Second):
+			close(release)
+			t.Fatalf("%s: Get waited for the stale load", name)
+		}
+		close(release)
+		<-done
+
+		if product, _, _ := c.Get(ctx, ids[0], loader.load); product.Name != "electric kettle" {
+			t.Errorf("%s: Get after the stale load = %q, want the new name", name, product.Name)
+		}
+		if opts.Remote != nil {
+			value, _ := server.Get("test:" + productKeyPrefix + ids[0])
+			if strings.Contains(value, `"name":"kettle"`) {
+				t.Errorf("%s: remote cache holds the stale product %s", name, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Remote is a cache shared between replicas. It stores opaque values so the
+// in-process and remote layers can use the same encoding.
+type Remote interface {
+	// Get returns the value stored for key, or false when missing.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// RedisRemote stores cache entries in Redis, or in any server speaking its
+// protocol such as a local stand-in during tests.
+type RedisRemote struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisRemote(client redis.UniversalClient, prefix string) *RedisRemote {
+	return &RedisRemote{client: client, prefix: prefix}
+}
+
+func (r *RedisRemote) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *RedisRemote) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *RedisRemote) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// Close releases the connections to the Redis server.
+func (r *RedisRemote) Close(ctx context.Context) error {
+	return r.client.Close()
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisRemote(t *testing.T) (*RedisRemote, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	remote := NewRedisRemote(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:")
+	t.Cleanup(func() { remote.Close(context.Background()) })
+	return remote, server
+}
+
+func TestRedisRemote(t *testing.T) {
+	ctx := context.Background()
+	remote, server := newTestRedisRemote(t)
+
+	if _, ok, err := remote.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("Get of a missing key = %t, %v, want a miss", ok, err)
+	}
+	if err := remote.Set(ctx, "a", []byte("one"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := remote.Set(ctx, "b", []byte("two"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, ok, err := remote.Get(ctx, "a")
+	if err != nil || !ok || string(value) != "one" {
+		t.Fatalf("Get = %q, %t, %v, want one", value, ok, err)
+	}
+
+	// Keys are stored under the prefix, with the TTL
+	if stored, err := server.Get("test:a"); err != nil || stored != "one" {
+		t.Errorf("stored test:a = %q, %v", stored, err)
+	}
+	if ttl := server.TTL("test:a"); ttl != time.Minute {
+		t.Errorf("TTL of test:a = %s, want 1m", ttl)
+	}
+
+	if err := remote.Delete(ctx); err != nil {
+		t.Errorf("Delete of no keys: %v", err)
+	}
+	if err := remote.Delete(ctx, "a", "b", "missing"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, ok, err := remote.Get(ctx, key); ok || err != nil {
+			t.Errorf("Get(%s) after Delete = %t, %v, want a miss", key, ok, err)
+		}
+	}
+}
+
+func TestRedisRemoteExpires(t *testing.T) {
+	ctx := context.Background()
+	remote, server := newTestRedisRemote(t)
+
+	if err := remote.Set(ctx, "a", []byte("one"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(999 * time.Millisecond)
+	if _, ok, _ := remote.Get(ctx, "a"); !ok {
+		t.Error("a expired early")
+	}
+	server.FastForward(time.Millisecond)
+	if _, ok, err := remote.Get(ctx, "a"); ok || err != nil {
+		t.Errorf("Get after the TTL = %t, %v, want a miss", ok, err)
+	}
+}
+
+func TestRedisRemoteErrors(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	// Without retries, the errors surface at once
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	remote := NewRedisRemote(client, "test:")
+	defer remote.Close(ctx)
+	server.Close()
+
+	if _, _, err := remote.Get(ctx, "a"); err == nil {
+		t.Error("Get succeeded with the server down")
+	}
+	if err := remote.Set(ctx, "a", []byte("one"), time.Minute); err == nil {
+		t.Error("Set succeeded with the server down")
+	}
+}
@@ -0,0 +1,951 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/blob"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/delivery"
+	"github.com/tittuvarghese/ss-go-product-service/core/duplicates"
+	"github.com/tittuvarghese/ss-go-product-service/core/images"
+	"github.com/tittuvarghese/ss-go-product-service/core/moderation"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"github.com/tittuvarghese/ss-go-product-service/core/shipping"
+	"github.com/tittuvarghese/ss-go-product-service/core/tracing"
+)
+
+// Config holds every setting of the product service. Values are resolved from
+// the defaults, then the config file, then environment variables and finally
+// command line flags, each source overriding the previous one.
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	TLS        TLSConfig        `yaml:"tls"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Logging    LoggingConfig    `yaml:"logging"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Cache      CacheConfig      `yaml:"cache"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Sellers    SellersConfig    `yaml:"sellers"`
+	Moderation ModerationConfig `yaml:"moderation"`
+	Reviews    ReviewsConfig    `yaml:"reviews"`
+	Questions  QuestionsConfig  `yaml:"questions"`
+	Events     EventsConfig     `yaml:"events"`
+	Duplicates DuplicatesConfig `yaml:"duplicates"`
+	Pricing    PricingConfig    `yaml:"pricing"`
+	Shipping   ShippingConfig   `yaml:"shipping"`
+	Delivery   DeliveryConfig   `yaml:"delivery"`
+	Images     ImagesConfig     `yaml:"images"`
+	Features   FeatureConfig    `yaml:"features"`
+}
+
+type ServerConfig struct {
+	GrpcPort             int           `yaml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port" usage:"port the gRPC server listens on"`
+	ConnectionTimeout    time.Duration `yaml:"connection_timeout" env:"GRPC_CONNECTION_TIMEOUT" usage:"deadline for new connections to complete the handshake"`
+	ShutdownDrainTimeout time.Duration `yaml:"shutdown_drain_timeout" env:"SHUTDOWN_DRAIN_TIMEOUT" flag:"shutdown-drain-timeout" usage:"time in-flight requests get to complete on shutdown"`
+}
+
+type DatabaseConfig struct {
+	Url             string        `yaml:"url" env:"DATABASE_URL" flag:"database-url" secret:"dsn" usage:"relational database connection string"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS" usage:"maximum number of open connections, 0 for unlimited"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS" usage:"maximum number of idle connections kept in the pool"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME" usage:"maximum time a connection may be reused"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME" usage:"maximum time a connection may sit idle"`
+	// ReplicaUrls lists read replicas of the primary. Reads are spread over the
+	// healthy ones while every write goes to the primary.
+	ReplicaUrls                []string      `yaml:"replica_urls" env:"DATABASE_REPLICA_URLS" secret:"dsn" usage:"comma separated read replica connection strings"`
+	ReplicaHealthCheckInterval time.Duration `yaml:"replica_health_check_interval" env:"DATABASE_REPLICA_HEALTH_CHECK_INTERVAL" usage:"how often read replicas are pinged"`
+	ReplicaFailureThreshold    int           `yaml:"replica_failure_threshold" env:"DATABASE_REPLICA_FAILURE_THRESHOLD" usage:"failed health checks after which a replica stops serving reads"`
+	ReadYourWritesWindow       time.Duration `yaml:"read_your_writes_window" env:"DATABASE_READ_YOUR_WRITES_WINDOW" usage:"how long reads after a write are served by the primary"`
+}
+
+type TLSConfig struct {
+	// Plaintext disables TLS entirely. It is meant for local development only.
+	Plaintext        bool             `yaml:"plaintext" env:"GRPC_PLAINTEXT" flag:"plaintext" usage:"serve gRPC without TLS, for local development only"`
+	CertFile         string           `yaml:"cert_file" env:"TLS_CERT_FILE" flag:"tls-cert" usage:"PEM encoded server certificate"`
+	KeyFile          string           `yaml:"key_file" env:"TLS_KEY_FILE" flag:"tls-key" usage:"PEM encoded server private key"`
+	ClientCAFile     string           `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE" flag:"tls-client-ca" usage:"PEM encoded CA bundle client certificates are verified against"`
+	ClientAuth       string           `yaml:"client_auth" env:"TLS_CLIENT_AUTH" usage:"client certificate policy: none, request or require"`
+	ClientIdentities []ClientIdentity `yaml:"client_identities"`
+	ReloadInterval   time.Duration    `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL" usage:"how often certificate files are checked for changes"`
+}
+
+// ClientIdentity maps a SAN (DNS name, URI or email) of a trusted client
+// certificate to the service identity it authenticates as.
+type ClientIdentity struct {
+	SAN      string `yaml:"san"`
+	Identity string `yaml:"identity"`
+}
+
+// IdentityMap indexes the configured client identities by SAN.
+func (c TLSConfig) IdentityMap() map[string]string {
+	identities := make(map[string]string, len(c.ClientIdentities))
+	for _, client := range c.ClientIdentities {
+		identities[client.SAN] = client.Identity
+	}
+	return identities
+}
+
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" env:"METRICS_ENABLED" usage:"serve Prometheus metrics"`
+	Port    int  `yaml:"port" env:"METRICS_PORT" flag:"metrics-port" usage:"port the metrics endpoint listens on"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" usage:"span exporter: none, otlp, stdout or file"`
+	FilePath    string  `yaml:"file_path" env:"TRACING_FILE_PATH" usage:"destination of the file span exporter"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"fraction of new traces which are recorded"`
+}
+
+type LoggingConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"minimum log level: debug, info, warn or error"`
+}
+
+type RateLimitConfig struct {
+	Enabled     bool              `yaml:"enabled" env:"RATE_LIMIT_ENABLED" usage:"limit the request rate per caller and method"`
+	Rate        float64           `yaml:"rate" env:"RATE_LIMIT_RATE" usage:"requests per second allowed per caller and method"`
+	Burst       int               `yaml:"burst" env:"RATE_LIMIT_BURST" usage:"requests a caller may issue at once above the rate"`
+	IdleTimeout time.Duration     `yaml:"idle_timeout" env:"RATE_LIMIT_IDLE_TIMEOUT" usage:"how long an unused bucket is kept"`
+	Methods     []MethodRateLimit `yaml:"methods"`
+}
+
+// MethodRateLimit overrides the default limit for one RPC.
+type MethodRateLimit struct {
+	Method string  `yaml:"method"`
+	Rate   float64 `yaml:"rate"`
+	Burst  int     `yaml:"burst"`
+}
+
+type CacheConfig struct {
+	Enabled     bool          `yaml:"enabled" env:"CACHE_ENABLED" usage:"cache product reads"`
+	Size        int           `yaml:"size" env:"CACHE_SIZE" usage:"maximum number of products cached in process"`
+	TTL         time.Duration `yaml:"ttl" env:"CACHE_TTL" usage:"how long a cached product may be served"`
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"CACHE_NEGATIVE_TTL" usage:"how long unknown product ids are remembered"`
+	// RedisAddr enables a cache shared between replicas when set.
+	RedisAddr     string `yaml:"redis_addr" env:"CACHE_REDIS_ADDR" usage:"address of the shared Redis cache, empty to disable"`
+	RedisPassword string `yaml:"redis_password" env:"CACHE_REDIS_PASSWORD" secret:"true" usage:"password of the shared Redis cache"`
+	RedisDB       int    `yaml:"redis_db" env:"CACHE_REDIS_DB" usage:"database number of the shared Redis cache"`
+}
+
+// AdminConfig controls who may call the admin RPCs, such as loading exchange
+// rates. Callers are identified by their mTLS client identity.
+type AdminConfig struct {
+	Identities []string `yaml:"identities" env:"ADMIN_IDENTITIES" usage:"comma separated client identities allowed to call admin RPCs"`
+	// AllowAll lets anyone call the admin RPCs. It is meant for local development only.
+	AllowAll bool `yaml:"allow_all" env:"ADMIN_ALLOW_ALL" usage:"allow every caller to use admin RPCs, for local development only"`
+}
+
+// SellersConfig controls which services are trusted to act for sellers.
+type SellersConfig struct {
+	// AgentIdentities are the mTLS client identities, such as the gateway,
+	// which authenticate sellers and pass on their seller_id. Only their
+	// requests see the listings of a seller which are not active.
+	AgentIdentities []string `yaml:"agent_identities" env:"SELLERS_AGENT_IDENTITIES" usage:"comma separated client identities trusted to pass on the seller_id of authenticated sellers"`
+	// AllowAllAgents trusts the seller_id of every caller. It is meant for
+	// local development only.
+	AllowAllAgents bool `yaml:"allow_all_agents" env:"SELLERS_ALLOW_ALL_AGENTS" usage:"trust the seller_id of every caller, for local development only"`
+}
+
+// ModerationConfig controls who may approve, reject and suspend products.
+// Moderators are identified by their mTLS client identity.
+type ModerationConfig struct {
+	Identities []string `yaml:"identities" env:"MODERATION_IDENTITIES" usage:"comma separated client identities allowed to moderate products"`
+	// AllowAll lets anyone moderate products. It is meant for local development only.
+	AllowAll bool                 `yaml:"allow_all" env:"MODERATION_ALLOW_ALL" usage:"allow every caller to moderate products, for local development only"`
+	Rules    ModerationRuleConfig `yaml:"rules"`
+}
+
+// ReviewsConfig controls the publication of product reviews and who may vouch
+// for the purchases behind them.
+type ReviewsConfig struct {
+	RequireApproval bool `yaml:"require_approval" env:"REVIEWS_REQUIRE_APPROVAL" usage:"hold new reviews for a moderator instead of publishing them right away"`
+	// VerifierIdentities are the mTLS client identities, such as the order
+	// service, allowed to flag reviews as verified purchases.
+	VerifierIdentities []string `yaml:"verifier_identities" env:"REVIEWS_VERIFIER_IDENTITIES" usage:"comma separated client identities allowed to flag verified purchases"`
+	// AllowAllVerifiers lets anyone flag verified purchases. It is meant for
+	// local development only.
+	AllowAllVerifiers bool `yaml:"allow_all_verifiers" env:"REVIEWS_ALLOW_ALL_VERIFIERS" usage:"allow every caller to flag verified purchases, for local development only"`
+}
+
+// QuestionsConfig controls the publication of product questions and their
+// answers.
+type QuestionsConfig struct {
+	RequireApproval bool `yaml:"require_approval" env:"QUESTIONS_REQUIRE_APPROVAL" usage:"hold new questions and answers for a moderator instead of publishing them right away"`
+}
+
+const (
+	EventsBackendLog   = "log"
+	EventsBackendRedis = "redis"
+)
+
+// EventsConfig controls the delivery of the events recorded in the outbox,
+// such as the questions notified to sellers.
+type EventsConfig struct {
+	// Backend is log to write the events to the log, or redis to append them
+	// to a Redis stream.
+	Backend       string        `yaml:"backend" env:"EVENTS_BACKEND" usage:"where events are published: log or redis"`
+	RedisAddr     string        `yaml:"redis_addr" env:"EVENTS_REDIS_ADDR" usage:"address of the Redis server events are published to"`
+	RedisPassword string        `yaml:"redis_password" env:"EVENTS_REDIS_PASSWORD" secret:"true" usage:"password of the Redis server events are published to"`
+	RedisDB       int           `yaml:"redis_db" env:"EVENTS_REDIS_DB" usage:"database number of the Redis server events are published to"`
+	Stream        string        `yaml:"stream" env:"EVENTS_STREAM" usage:"Redis stream events are appended to"`
+	MaxLen        int           `yaml:"max_len" env:"EVENTS_MAX_LEN" usage:"approximate number of events kept in the Redis stream"`
+	RelayInterval time.Duration `yaml:"relay_interval" env:"EVENTS_RELAY_INTERVAL" usage:"how often events left in the outbox are published"`
+	BatchSize     int           `yaml:"batch_size" env:"EVENTS_BATCH_SIZE" usage:"number of events published at once"`
+}
+
+// ModerationRuleConfig holds the rules products are checked against on
+// create, update and submission. Each matching rule adds its score to the
+// risk score of the product.
+type ModerationRuleConfig struct {
+	Enabled     bool `yaml:"enabled" env:"MODERATION_RULES_ENABLED" usage:"check products against the moderation rules"`
+	ReviewScore int  `yaml:"review_score" env:"MODERATION_REVIEW_SCORE" usage:"risk score sending products to manual review"`
+	RejectScore int  `yaml:"reject_score" env:"MODERATION_REJECT_SCORE" usage:"risk score rejecting products"`
+	// Keywords match whole words regardless of case, patterns are regular
+	// expressions. Both are checked against the name, type and category.
+	Keywords   []KeywordRule  `yaml:"keywords"`
+	Patterns   []PatternRule  `yaml:"patterns"`
+	Categories []CategoryRule `yaml:"categories"`
+}
+
+type KeywordRule struct {
+	Name  string   `yaml:"name"`
+	Words []string `yaml:"words"`
+	Score int      `yaml:"score"`
+}
+
+type PatternRule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	Score   int    `yaml:"score"`
+}
+
+// CategoryRule restricts a category to the allowed sellers.
+type CategoryRule struct {
+	Category       string   `yaml:"category"`
+	Score          int      `yaml:"score"`
+	AllowedSellers []string `yaml:"allowed_sellers"`
+}
+
+// Engine compiles the rules, it returns nil when the rules are disabled.
+func (c ModerationRuleConfig) Engine() (*moderation.Rules, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	rules := &moderation.Rules{ReviewScore: c.ReviewScore, RejectScore: c.RejectScore}
+	for _, keyword := range c.Keywords {
+		rules.Text = append(rules.Text, moderation.KeywordRule(keyword.Name, keyword.Words, keyword.Score))
+	}
+	for _, pattern := range c.Patterns {
+		compiled, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("moderation rule %s: %w", pattern.Name, err)
+		}
+		rules.Text = append(rules.Text, moderation.Rule{Name: pattern.Name, Pattern: compiled, Score: pattern.Score})
+	}
+	for _, category := range c.Categories {
+		allowed := make(map[string]bool, len(category.AllowedSellers))
+		for _, seller := range category.AllowedSellers {
+			allowed[seller] = true
+		}
+		rules.Categories = append(rules.Categories, moderation.CategoryRule{
+			Name:           "restricted category " + category.Category,
+			Category:       category.Category,
+			Score:          category.Score,
+			AllowedSellers: allowed,
+		})
+	}
+	return rules, nil
+}
+
+// DuplicatesConfig controls the detection of near-duplicate listings: products
+// of the same category with similar names, prices and dimensions.
+type DuplicatesConfig struct {
+	// Mode applies to new products duplicating a listing of the same seller,
+	// off skips the check, warn reports the duplicates and block refuses the
+	// product.
+	Mode           string  `yaml:"mode" env:"DUPLICATES_MODE" usage:"check of new products against the listings of their seller: off, warn or block"`
+	Threshold      float64 `yaml:"threshold" env:"DUPLICATES_THRESHOLD" usage:"name similarity from 0 to 1 from which products are duplicates"`
+	PriceTolerance float64 `yaml:"price_tolerance" env:"DUPLICATES_PRICE_TOLERANCE" usage:"relative price difference allowed between duplicates"`
+	SizeTolerance  float64 `yaml:"size_tolerance" env:"DUPLICATES_SIZE_TOLERANCE" usage:"relative difference of each dimension and the weight allowed between duplicates"`
+}
+
+func (c DuplicatesConfig) Detector() duplicates.Detector {
+	return duplicates.Detector{
+		Threshold:      c.Threshold,
+		PriceTolerance: c.PriceTolerance,
+		SizeTolerance:  c.SizeTolerance,
+	}
+}
+
+type PricingConfig struct {
+	FxRatesFile string `yaml:"fx_rates_file" env:"PRICING_FX_RATES_FILE" usage:"YAML file of exchange rates loaded on startup"`
+	// Rounding turns converted prices into the price points of a currency.
+	Rounding []RoundingRule `yaml:"rounding"`
+	// ScheduleRefreshInterval bounds how long other replicas take to apply a
+	// new or cancelled price schedule.
+	ScheduleRefreshInterval time.Duration `yaml:"schedule_refresh_interval" env:"PRICING_SCHEDULE_REFRESH_INTERVAL" usage:"how often price schedules are reloaded"`
+}
+
+// RoundingRule moves converted prices of a currency to an amount whose
+// remainder modulo increment is ending, both in minor units.
+type RoundingRule struct {
+	Currency  string `yaml:"currency"`
+	Increment int64  `yaml:"increment"`
+	Ending    int64  `yaml:"ending"`
+	Mode      string `yaml:"mode"`
+}
+
+// RoundingRules indexes the rounding rules by currency. Mode defaults to
+// nearest and increment to one minor unit.
+func (c PricingConfig) RoundingRules() pricing.Rounding {
+	rules := make(pricing.Rounding, len(c.Rounding))
+	for _, rule := range c.Rounding {
+		rules[rule.Currency] = rule.rule()
+	}
+	return rules
+}
+
+func (r RoundingRule) rule() pricing.RoundingRule {
+	rule := pricing.RoundingRule{Increment: r.Increment, Ending: r.Ending, Mode: r.Mode}
+	if rule.Increment == 0 {
+		rule.Increment = 1
+	}
+	if rule.Mode == "" {
+		rule.Mode = pricing.RoundNearest
+	}
+	return rule
+}
+
+// ShippingConfig holds the rate tables shipping quotes are computed with.
+// Sizes are in centimetres and weights in kilograms.
+type ShippingConfig struct {
+	VolumetricDivisor float64        `yaml:"volumetric_divisor" env:"SHIPPING_VOLUMETRIC_DIVISOR" usage:"cubic centimetres per kilogram of volumetric weight"`
+	Zones             []ShippingZone `yaml:"zones"`
+}
+
+// ShippingZone is the rate table of a destination zone, amounts in minor
+// units of its currency.
+type ShippingZone struct {
+	Name     string `yaml:"name"`
+	Currency string `yaml:"currency"`
+	// Rates are ordered by increasing max weight.
+	Rates []ShippingRate `yaml:"rates"`
+	// ExtraKgAmount is charged for every started kilogram above the heaviest
+	// rate, heavier shipments are rejected when it is zero.
+	ExtraKgAmount int64 `yaml:"extra_kg_amount"`
+}
+
+// ShippingRate is the charge for shipments of up to MaxWeight kilograms.
+type ShippingRate struct {
+	MaxWeight float64 `yaml:"max_weight"`
+	Amount    int64   `yaml:"amount"`
+}
+
+// ShippingRates indexes the zones by name.
+func (c ShippingConfig) ShippingRates() shipping.Rates {
+	rates := shipping.Rates{
+		VolumetricDivisor: c.VolumetricDivisor,
+		Zones:             make(map[string]shipping.Zone, len(c.Zones)),
+	}
+	for _, zone := range c.Zones {
+		rates.Zones[zone.Name] = zone.zone()
+	}
+	return rates
+}
+
+func (z ShippingZone) zone() shipping.Zone {
+	zone := shipping.Zone{Currency: z.Currency, ExtraKgAmount: z.ExtraKgAmount}
+	for _, rate := range z.Rates {
+		zone.Rates = append(zone.Rates, shipping.Rate{MaxWeight: rate.MaxWeight, Amount: rate.Amount})
+	}
+	return zone
+}
+
+// DeliveryConfig holds the calendars, seller handling times and zone transit
+// times delivery dates are estimated with. The handling settings at the top
+// level apply to every seller not listed in Sellers.
+type DeliveryConfig struct {
+	HandlingDays int    `yaml:"handling_days" env:"DELIVERY_HANDLING_DAYS" usage:"business days sellers take to dispatch an order of a product without base delivery timelines"`
+	Cutoff       string `yaml:"cutoff" env:"DELIVERY_CUTOFF" usage:"local time (HH:MM) orders must be placed before to count the day"`
+	TimeZone     string `yaml:"time_zone" env:"DELIVERY_TIME_ZONE" usage:"IANA time zone of the cut-off of sellers"`
+	Calendar     string `yaml:"calendar" env:"DELIVERY_CALENDAR" usage:"calendar of the business days of sellers"`
+	// Calendars are loaded from YAML files of weekend days and holidays.
+	Calendars []DeliveryCalendar `yaml:"calendars"`
+	Sellers   []SellerHandling   `yaml:"sellers"`
+	Zones     []DeliveryZone     `yaml:"zones"`
+}
+
+type DeliveryCalendar struct {
+	Name string `yaml:"name"`
+	File string `yaml:"file"`
+}
+
+// SellerHandling overrides the handling settings for one seller. Empty
+// cut-off, time zone and calendar fall back to the top level settings.
+type SellerHandling struct {
+	SellerId     string `yaml:"seller_id"`
+	HandlingDays int    `yaml:"handling_days"`
+	Cutoff       string `yaml:"cutoff"`
+	TimeZone     string `yaml:"time_zone"`
+	Calendar     string `yaml:"calendar"`
+}
+
+// DeliveryZone holds the transit times of a destination zone in business
+// days of its calendar.
+type DeliveryZone struct {
+	Name           string `yaml:"name"`
+	MinTransitDays int    `yaml:"min_transit_days"`
+	MaxTransitDays int    `yaml:"max_transit_days"`
+	TimeZone       string `yaml:"time_zone"`
+	Calendar       string `yaml:"calendar"`
+}
+
+// Estimator loads the calendars and time zones and builds the estimator of
+// delivery dates. An empty calendar name stands for Saturday and Sunday off.
+func (c DeliveryConfig) Estimator() (delivery.Estimator, error) {
+	calendars := map[string]*delivery.Calendar{"": nil}
+	for _, calendar := range c.Calendars {
+		loaded, err := delivery.ReadCalendarFile(calendar.File)
+		if err != nil {
+			return delivery.Estimator{}, err
+		}
+		calendars[calendar.Name] = loaded
+	}
+
+	estimator := delivery.Estimator{
+		Sellers: make(map[string]delivery.Handling, len(c.Sellers)),
+		Zones:   make(map[string]delivery.Transit, len(c.Zones)),
+	}
+	var err error
+	estimator.Default, err = handling(c.HandlingDays, c.Cutoff, c.TimeZone, c.Calendar, calendars)
+	if err != nil {
+		return delivery.Estimator{}, err
+	}
+	for _, seller := range c.Sellers {
+		seller = c.inherit(seller)
+		estimator.Sellers[seller.SellerId], err = handling(seller.HandlingDays, seller.Cutoff, seller.TimeZone, seller.Calendar, calendars)
+		if err != nil {
+			return delivery.Estimator{}, fmt.Errorf("seller %s: %w", seller.SellerId, err)
+		}
+	}
+	for _, zone := range c.Zones {
+		location, err := time.LoadLocation(zone.TimeZone)
+		if err != nil {
+			return delivery.Estimator{}, fmt.Errorf("zone %s: %w", zone.Name, err)
+		}
+		calendar, ok := calendars[zone.Calendar]
+		if !ok {
+			return delivery.Estimator{}, fmt.Errorf("zone %s: unknown calendar %q", zone.Name, zone.Calendar)
+		}
+		estimator.Zones[zone.Name] = delivery.Transit{
+			MinDays:  zone.MinTransitDays,
+			MaxDays:  zone.MaxTransitDays,
+			Location: location,
+			Calendar: calendar,
+		}
+	}
+	return estimator, nil
+}
+
+func (c DeliveryConfig) inherit(seller SellerHandling) SellerHandling {
+	if seller.Cutoff == "" {
+		seller.Cutoff = c.Cutoff
+	}
+	if seller.TimeZone == "" {
+		seller.TimeZone = c.TimeZone
+	}
+	if seller.Calendar == "" {
+		seller.Calendar = c.Calendar
+	}
+	return seller
+}
+
+func handling(days int, cutoff string, timeZone string, calendarName string, calendars map[string]*delivery.Calendar) (delivery.Handling, error) {
+	at, err := parseCutoff(cutoff)
+	if err != nil {
+		return delivery.Handling{}, err
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return delivery.Handling{}, err
+	}
+	calendar, ok := calendars[calendarName]
+	if !ok {
+		return delivery.Handling{}, fmt.Errorf("unknown calendar %q", calendarName)
+	}
+	return delivery.Handling{Days: days, Cutoff: at, Location: location, Calendar: calendar}, nil
+}
+
+// parseCutoff turns a HH:MM local time into its offset from midnight. An
+// empty cut-off lets orders count the whole day.
+func parseCutoff(cutoff string) (time.Duration, error) {
+	if cutoff == "" {
+		return 24 * time.Hour, nil
+	}
+	at, err := time.Parse("15:04", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("cut-off %q is not a HH:MM time", cutoff)
+	}
+	return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute, nil
+}
+
+type FeatureConfig struct {
+	Reflection       bool `yaml:"reflection" env:"FEATURE_REFLECTION" usage:"register the gRPC reflection service"`
+	MigrateOnStartup bool `yaml:"migrate_on_startup" env:"FEATURE_MIGRATE_ON_STARTUP" usage:"apply pending database migrations on startup"`
+}
+
+// Blob store backends of product images
+const (
+	BlobBackendLocal = "local"
+	BlobBackendS3    = "s3"
+)
+
+// ImagesConfig controls the storage and limits of product images.
+type ImagesConfig struct {
+	Backend       string `yaml:"backend" env:"IMAGES_BACKEND" usage:"where image content is stored: local or s3"`
+	MaxSize       int    `yaml:"max_size" env:"IMAGES_MAX_SIZE" usage:"largest image accepted, in bytes"`
+	MaxPerProduct int    `yaml:"max_per_product" env:"IMAGES_MAX_PER_PRODUCT" usage:"number of images a product may have"`
+	// BaseURL is where clients download the images from, e.g. a CDN in front
+	// of the local directory or the bucket. It defaults to /images with the
+	// local backend and to the bucket URL with the s3 backend.
+	BaseURL    string            `yaml:"base_url" env:"IMAGES_BASE_URL" usage:"URL prefix clients download images from"`
+	Local      LocalImagesConfig `yaml:"local"`
+	S3         S3ImagesConfig    `yaml:"s3"`
+	Renditions RenditionsConfig  `yaml:"renditions"`
+}
+
+type LocalImagesConfig struct {
+	Dir string `yaml:"dir" env:"IMAGES_LOCAL_DIR" usage:"directory images are stored in with the local backend"`
+}
+
+// S3ImagesConfig points to an S3 compatible bucket, such as MinIO for local
+// development.
+type S3ImagesConfig struct {
+	Endpoint        string `yaml:"endpoint" env:"IMAGES_S3_ENDPOINT" usage:"base URL of the S3 compatible object store"`
+	Region          string `yaml:"region" env:"IMAGES_S3_REGION" usage:"region of the bucket"`
+	Bucket          string `yaml:"bucket" env:"IMAGES_S3_BUCKET" usage:"bucket images are stored in"`
+	AccessKeyId     string `yaml:"access_key_id" env:"IMAGES_S3_ACCESS_KEY_ID" usage:"access key id of the object store"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"IMAGES_S3_SECRET_ACCESS_KEY" secret:"true" usage:"secret access key of the object store"`
+	PathStyle       bool   `yaml:"path_style" env:"IMAGES_S3_PATH_STYLE" usage:"address the bucket in the path rather than the host name"`
+}
+
+// RenditionsConfig controls the resized copies rendered in the background
+// for each stored image. Sizes are the longest side, in pixels.
+type RenditionsConfig struct {
+	Workers       int           `yaml:"workers" env:"IMAGES_RENDITIONS_WORKERS" usage:"number of images rendered at once"`
+	QueueSize     int           `yaml:"queue_size" env:"IMAGES_RENDITIONS_QUEUE_SIZE" usage:"number of images waiting for a worker"`
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"IMAGES_RENDITIONS_SWEEP_INTERVAL" usage:"how often images still waiting for renditions are queued"`
+	ThumbnailSize int           `yaml:"thumbnail_size" env:"IMAGES_RENDITIONS_THUMBNAIL_SIZE" usage:"longest side of thumbnails"`
+	MediumSize    int           `yaml:"medium_size" env:"IMAGES_RENDITIONS_MEDIUM_SIZE" usage:"longest side of medium renditions"`
+	LargeSize     int           `yaml:"large_size" env:"IMAGES_RENDITIONS_LARGE_SIZE" usage:"longest side of large renditions"`
+	JpegQuality   int           `yaml:"jpeg_quality" env:"IMAGES_RENDITIONS_JPEG_QUALITY" usage:"JPEG quality of renditions, from 1 to 100"`
+}
+
+func (c RenditionsConfig) Specs() []images.Spec {
+	return []images.Spec{
+		{Name: images.RenditionThumbnail, MaxSize: c.ThumbnailSize},
+		{Name: images.RenditionMedium, MaxSize: c.MediumSize},
+		{Name: images.RenditionLarge, MaxSize: c.LargeSize},
+	}
+}
+
+// BlobStore opens the blob store of the configured backend.
+func (c ImagesConfig) BlobStore() (blob.BlobStore, error) {
+	if c.Backend == BlobBackendS3 {
+		return blob.NewS3Store(blob.S3Options{
+			Endpoint:        c.S3.Endpoint,
+			Region:          c.S3.Region,
+			Bucket:          c.S3.Bucket,
+			AccessKeyId:     c.S3.AccessKeyId,
+			SecretAccessKey: c.S3.SecretAccessKey,
+			PathStyle:       c.S3.PathStyle,
+			BaseURL:         c.BaseURL,
+		})
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = "/images"
+	}
+	return blob.NewLocalStore(c.Local.Dir, baseURL)
+}
+
+func (c ImagesConfig) Limits() images.Limits {
+	return images.Limits{MaxSize: int64(c.MaxSize), MaxPerProduct: c.MaxPerProduct}
+}
+
+// Default returns the configuration used when no source overrides a value.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			GrpcPort:             8083,
+			ConnectionTimeout:    120 * time.Second,
+			ShutdownDrainTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+
+			ReplicaHealthCheckInterval: 5 * time.Second,
+			ReplicaFailureThreshold:    3,
+			ReadYourWritesWindow:       5 * time.Second,
+		},
+		TLS: TLSConfig{
+			ClientAuth:     security.ClientAuthNone,
+			ReloadInterval: time.Minute,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Port:    9083,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1.0,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:     true,
+			Rate:        50,
+			Burst:       100,
+			IdleTimeout: 10 * time.Minute,
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			Size:        10000,
+			TTL:         time.Minute,
+			NegativeTTL: 10 * time.Second,
+		},
+		Pricing: PricingConfig{
+			ScheduleRefreshInterval: 30 * time.Second,
+		},
+		Moderation: ModerationConfig{
+			Rules: ModerationRuleConfig{
+				ReviewScore: 30,
+				RejectScore: 100,
+			},
+		},
+		Events: EventsConfig{
+			Backend:       EventsBackendLog,
+			Stream:        "product-service:events",
+			MaxLen:        100000,
+			RelayInterval: 5 * time.Second,
+			BatchSize:     100,
+		},
+		Duplicates: DuplicatesConfig{
+			Mode:           duplicates.ModeWarn,
+			Threshold:      0.8,
+			PriceTolerance: 0.1,
+			SizeTolerance:  0.1,
+		},
+		Shipping: ShippingConfig{
+			VolumetricDivisor: shipping.DefaultVolumetricDivisor,
+		},
+		Delivery: DeliveryConfig{
+			HandlingDays: 1,
+			Cutoff:       "15:00",
+			TimeZone:     "UTC",
+		},
+		Images: ImagesConfig{
+			Backend:       BlobBackendLocal,
+			MaxSize:       10 << 20,
+			MaxPerProduct: 10,
+			Local: LocalImagesConfig{
+				Dir: "data/images",
+			},
+			Renditions: RenditionsConfig{
+				Workers:       2,
+				QueueSize:     100,
+				SweepInterval: time.Minute,
+				ThumbnailSize: 150,
+				MediumSize:    600,
+				LargeSize:     1200,
+				JpegQuality:   85,
+			},
+		},
+		Features: FeatureConfig{
+			Reflection:       true,
+			MigrateOnStartup: true,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.GrpcPort), "server.grpc_port: %d is not a valid port", c.Server.GrpcPort)
+	check(c.Server.ConnectionTimeout > 0, "server.connection_timeout: must be positive")
+	check(c.Server.ShutdownDrainTimeout > 0, "server.shutdown_drain_timeout: must be positive")
+
+	if c.Database.Url == "" {
+		check(false, "database.url: is required")
+	} else if _, _, err := database.ParseDSN(c.Database.Url); err != nil {
+		check(false, "database.url: %v", err)
+	}
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns: must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns: must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns: %d exceeds max_open_conns %d", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime: must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time: must not be negative")
+	for i, replica := range c.Database.ReplicaUrls {
+		dialect, _, err := database.ParseDSN(replica)
+		if err != nil {
+			check(false, "database.replica_urls[%d]: %v", i, err)
+			continue
+		}
+		if primary, _, err := database.ParseDSN(c.Database.Url); err == nil {
+			check(dialect == primary, "database.replica_urls[%d]: %s replica of a %s primary", i, dialect, primary)
+		}
+	}
+	if len(c.Database.ReplicaUrls) > 0 {
+		check(c.Database.ReplicaHealthCheckInterval > 0, "database.replica_health_check_interval: must be positive")
+		check(c.Database.ReplicaFailureThreshold >= 1, "database.replica_failure_threshold: must be at least 1")
+		check(c.Database.ReadYourWritesWindow >= 0, "database.read_your_writes_window: must not be negative")
+	}
+
+	if !c.TLS.Plaintext {
+		check(fileExists(c.TLS.CertFile), "tls.cert_file: %q is not a readable file", c.TLS.CertFile)
+		check(fileExists(c.TLS.KeyFile), "tls.key_file: %q is not a readable file", c.TLS.KeyFile)
+		if c.TLS.ClientCAFile != "" {
+			check(fileExists(c.TLS.ClientCAFile), "tls.client_ca_file: %q is not a readable file", c.TLS.ClientCAFile)
+		}
+		switch c.TLS.ClientAuth {
+		case security.ClientAuthNone:
+		case security.ClientAuthRequest, security.ClientAuthRequire:
+			check(c.TLS.ClientCAFile != "", "tls.client_ca_file: is required to verify client certificates")
+		default:
+			check(false, "tls.client_auth: unknown policy %q", c.TLS.ClientAuth)
+		}
+		check(len(c.TLS.ClientIdentities) == 0 || c.TLS.ClientAuth == security.ClientAuthRequire,
+			"tls.client_identities: requires client_auth to be require")
+		for i, client := range c.TLS.ClientIdentities {
+			check(client.SAN != "" && client.Identity != "", "tls.client_identities[%d]: san and identity are required", i)
+		}
+		check(c.TLS.ReloadInterval > 0, "tls.reload_interval: must be positive")
+	}
+
+	if c.Metrics.Enabled {
+		check(validPort(c.Metrics.Port), "metrics.port: %d is not a valid port", c.Metrics.Port)
+		check(c.Metrics.Port != c.Server.GrpcPort, "metrics.port: must differ from server.grpc_port")
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOtlp, tracing.ExporterStdout:
+	case tracing.ExporterFile:
+		check(c.Tracing.FilePath != "", "tracing.file_path: is required by the file exporter")
+	default:
+		check(false, "tracing.exporter: unknown exporter %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1")
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.Rate >= 0, "rate_limit.rate: must not be negative")
+		check(c.RateLimit.Rate == 0 || c.RateLimit.Burst >= 1, "rate_limit.burst: must be at least 1")
+		check(c.RateLimit.IdleTimeout > 0, "rate_limit.idle_timeout: must be positive")
+		for i, method := range c.RateLimit.Methods {
+			check(strings.HasPrefix(method.Method, "/"), "rate_limit.methods[%d].method: %q is not a full method name", i, method.Method)
+			check(method.Rate >= 0, "rate_limit.methods[%d].rate: must not be negative", i)
+			check(method.Rate == 0 || method.Burst >= 1, "rate_limit.methods[%d].burst: must be at least 1", i)
+		}
+	}
+
+	if c.Cache.Enabled {
+		check(c.Cache.Size > 0, "cache.size: must be positive")
+		check(c.Cache.TTL > 0, "cache.ttl: must be positive")
+		check(c.Cache.NegativeTTL > 0, "cache.negative_ttl: must be positive")
+		check(c.Cache.RedisDB >= 0, "cache.redis_db: must not be negative")
+	}
+
+	check(!c.Admin.AllowAll || c.TLS.Plaintext, "admin.allow_all: is only allowed with tls.plaintext")
+	for i, identity := range c.Admin.Identities {
+		check(identity != "", "admin.identities[%d]: must not be empty", i)
+	}
+
+	check(!c.Sellers.AllowAllAgents || c.TLS.Plaintext, "sellers.allow_all_agents: is only allowed with tls.plaintext")
+	for i, identity := range c.Sellers.AgentIdentities {
+		check(identity != "", "sellers.agent_identities[%d]: must not be empty", i)
+	}
+
+	check(!c.Moderation.AllowAll || c.TLS.Plaintext, "moderation.allow_all: is only allowed with tls.plaintext")
+	for i, identity := range c.Moderation.Identities {
+		check(identity != "", "moderation.identities[%d]: must not be empty", i)
+	}
+	check(!c.Reviews.AllowAllVerifiers || c.TLS.Plaintext, "reviews.allow_all_verifiers: is only allowed with tls.plaintext")
+	for i, identity := range c.Reviews.VerifierIdentities {
+		check(identity != "", "reviews.verifier_identities[%d]: must not be empty", i)
+	}
+
+	switch c.Events.Backend {
+	case EventsBackendLog:
+	case EventsBackendRedis:
+		check(c.Events.RedisAddr != "", "events.redis_addr: is required with the redis backend")
+		check(c.Events.RedisDB >= 0, "events.redis_db: must not be negative")
+		check(c.Events.Stream != "", "events.stream: is required with the redis backend")
+		check(c.Events.MaxLen > 0, "events.max_len: must be positive")
+	default:
+		check(false, "events.backend: must be log or redis, got %q", c.Events.Backend)
+	}
+	check(c.Events.RelayInterval > 0, "events.relay_interval: must be positive")
+	check(c.Events.BatchSize > 0, "events.batch_size: must be positive")
+
+	if rules := c.Moderation.Rules; rules.Enabled {
+		check(rules.ReviewScore > 0, "moderation.rules.review_score: must be positive")
+		check(rules.RejectScore >= rules.ReviewScore, "moderation.rules.reject_score: must be at least review_score")
+		for i, keyword := range rules.Keywords {
+			check(keyword.Name != "", "moderation.rules.keywords[%d].name: must not be empty", i)
+			check(len(keyword.Words) > 0, "moderation.rules.keywords[%d].words: at least one word is required", i)
+			for j, word := range keyword.Words {
+				check(strings.TrimSpace(word) != "", "moderation.rules.keywords[%d].words[%d]: must not be empty", i, j)
+			}
+			check(keyword.Score > 0, "moderation.rules.keywords[%d].score: must be positive", i)
+		}
+		for i, pattern := range rules.Patterns {
+			check(pattern.Name != "", "moderation.rules.patterns[%d].name: must not be empty", i)
+			if _, err := regexp.Compile(pattern.Pattern); err != nil || pattern.Pattern == "" {
+				check(false, "moderation.rules.patterns[%d].pattern: %q is not a valid regular expression", i, pattern.Pattern)
+			}
+			check(pattern.Score > 0, "moderation.rules.patterns[%d].score: must be positive", i)
+		}
+		for i, category := range rules.Categories {
+			check(category.Category != "", "moderation.rules.categories[%d].category: must not be empty", i)
+			check(category.Score > 0, "moderation.rules.categories[%d].score: must be positive", i)
+		}
+	}
+
+	switch c.Duplicates.Mode {
+	case duplicates.ModeOff, duplicates.ModeWarn, duplicates.ModeBlock:
+	default:
+		check(false, "duplicates.mode: must be off, warn or block, got %q", c.Duplicates.Mode)
+	}
+	check(c.Duplicates.Threshold > 0 && c.Duplicates.Threshold <= 1, "duplicates.threshold: must be above 0 and at most 1")
+	check(c.Duplicates.PriceTolerance >= 0 && c.Duplicates.PriceTolerance < 1, "duplicates.price_tolerance: must be at least 0 and below 1")
+	check(c.Duplicates.SizeTolerance >= 0 && c.Duplicates.SizeTolerance < 1, "duplicates.size_tolerance: must be at least 0 and below 1")
+
+	if c.Pricing.FxRatesFile != "" {
+		check(fileExists(c.Pricing.FxRatesFile), "pricing.fx_rates_file: %q is not a readable file", c.Pricing.FxRatesFile)
+	}
+	check(c.Pricing.ScheduleRefreshInterval > 0, "pricing.schedule_refresh_interval: must be positive")
+	for i, rule := range c.Pricing.Rounding {
+		if _, err := money.MinorUnits(rule.Currency); err != nil {
+			check(false, "pricing.rounding[%d].currency: %v", i, err)
+		}
+		if err := rule.rule().Validate(); err != nil {
+			check(false, "pricing.rounding[%d]: %v", i, err)
+		}
+	}
+
+	check(c.Shipping.VolumetricDivisor > 0, "shipping.volumetric_divisor: must be positive")
+	zones := map[string]bool{}
+	for i, zone := range c.Shipping.Zones {
+		check(zone.Name != "", "shipping.zones[%d].name: must not be empty", i)
+		check(!zones[zone.Name], "shipping.zones[%d].name: zone %q is defined twice", i, zone.Name)
+		zones[zone.Name] = true
+		if err := zone.zone().Validate(); err != nil {
+			check(false, "shipping.zones[%d]: %v", i, err)
+		}
+	}
+
+	check(c.Delivery.HandlingDays >= 0, "delivery.handling_days: must not be negative")
+	calendars := map[string]bool{"": true}
+	for i, calendar := range c.Delivery.Calendars {
+		check(calendar.Name != "", "delivery.calendars[%d].name: must not be empty", i)
+		check(!calendars[calendar.Name], "delivery.calendars[%d].name: calendar %q is defined twice", i, calendar.Name)
+		calendars[calendar.Name] = true
+		check(fileExists(calendar.File), "delivery.calendars[%d].file: %q is not a readable file", i, calendar.File)
+	}
+	checkHandling := func(prefix string, days int, cutoff string, timeZone string, calendar string) {
+		check(days >= 0 && days <= delivery.MaxDays, "%shandling_days: must be between 0 and %d", prefix, delivery.MaxDays)
+		if _, err := parseCutoff(cutoff); err != nil {
+			check(false, "%scutoff: %v", prefix, err)
+		}
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			check(false, "%stime_zone: %v", prefix, err)
+		}
+		check(calendars[calendar], "%scalendar: unknown calendar %q", prefix, calendar)
+	}
+	checkHandling("delivery.", c.Delivery.HandlingDays, c.Delivery.Cutoff, c.Delivery.TimeZone, c.Delivery.Calendar)
+	sellers := map[string]bool{}
+	for i, seller := range c.Delivery.Sellers {
+		prefix := fmt.Sprintf("delivery.sellers[%d].", i)
+		check(seller.SellerId != "", "%sseller_id: must not be empty", prefix)
+		check(!sellers[seller.SellerId], "%sseller_id: seller %q is listed twice", prefix, seller.SellerId)
+		sellers[seller.SellerId] = true
+		seller = c.Delivery.inherit(seller)
+		checkHandling(prefix, seller.HandlingDays, seller.Cutoff, seller.TimeZone, seller.Calendar)
+	}
+	deliveryZones := map[string]bool{}
+	for i, zone := range c.Delivery.Zones {
+		prefix := fmt.Sprintf("delivery.zones[%d].", i)
+		check(zone.Name != "", "%sname: must not be empty", prefix)
+		check(!deliveryZones[zone.Name], "%sname: zone %q is defined twice", prefix, zone.Name)
+		deliveryZones[zone.Name] = true
+		check(zone.MinTransitDays >= 0, "%smin_transit_days: must not be negative", prefix)
+		check(zone.MaxTransitDays >= zone.MinTransitDays, "%smax_transit_days: must be at least min_transit_days", prefix)
+		check(zone.MaxTransitDays <= delivery.MaxDays, "%smax_transit_days: must not exceed %d", prefix, delivery.MaxDays)
+		if _, err := time.LoadLocation(zone.TimeZone); err != nil {
+			check(false, "%stime_zone: %v", prefix, err)
+		}
+		check(calendars[zone.Calendar], "%scalendar: unknown calendar %q", prefix, zone.Calendar)
+	}
+
+	switch c.Images.Backend {
+	case BlobBackendLocal:
+		check(c.Images.Local.Dir != "", "images.local.dir: is required with the local backend")
+	case BlobBackendS3:
+		if endpoint, err := url.Parse(c.Images.S3.Endpoint); err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+			check(false, "images.s3.endpoint: %q is not an http or https URL", c.Images.S3.Endpoint)
+		}
+		check(c.Images.S3.Region != "", "images.s3.region: is required with the s3 backend")
+		check(c.Images.S3.Bucket != "", "images.s3.bucket: is required with the s3 backend")
+		check(c.Images.S3.AccessKeyId != "", "images.s3.access_key_id: is required with the s3 backend")
+		check(c.Images.S3.SecretAccessKey != "", "images.s3.secret_access_key: is required with the s3 backend")
+	default:
+		check(false, "images.backend: must be local or s3, got %q", c.Images.Backend)
+	}
+	check(c.Images.MaxSize > 0, "images.max_size: must be positive")
+	check(c.Images.MaxPerProduct > 0, "images.max_per_product: must be positive")
+	renditions := c.Images.Renditions
+	check(renditions.Workers > 0, "images.renditions.workers: must be positive")
+	check(renditions.QueueSize > 0, "images.renditions.queue_size: must be positive")
+	check(renditions.SweepInterval > 0, "images.renditions.sweep_interval: must be positive")
+	check(renditions.ThumbnailSize > 0, "images.renditions.thumbnail_size: must be positive")
+	check(renditions.MediumSize > renditions.ThumbnailSize, "images.renditions.medium_size: must be larger than thumbnail_size")
+	check(renditions.LargeSize > renditions.MediumSize, "images.renditions.large_size: must be larger than medium_size")
+	check(renditions.JpegQuality >= 1 && renditions.JpegQuality <= 100, "images.renditions.jpeg_quality: must be between 1 and 100")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Logging.Level)) == nil, "logging.level: unknown level %q", c.Logging.Level)
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnvName points to the YAML config file when no -config flag is given.
+const ConfigFileEnvName = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load resolves the configuration from the defaults, the config file, the
+// environment (read through lookup) and the command line flags in args. The
+// arguments left after the flags are returned alongside the configuration.
+func Load(name string, args []string, lookup func(string) string) (Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML config file")
+	overrides := map[string]string{}
+	walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, _ reflect.Value) error {
+		flagName := field.Tag.Get("flag")
+		if flagName == "" {
+			return nil
+		}
+		record := func(value string) error {
+			overrides[flagName] = value
+			return nil
+		}
+		if field.Type.Kind() == reflect.Bool {
+			fs.BoolFunc(flagName, field.Tag.Get("usage"), record)
+		} else {
+			fs.Func(flagName, field.Tag.Get("usage"), record)
+		}
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = lookup(ConfigFileEnvName)
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	err := walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) error {
+		envName := field.Tag.Get("env")
+		if envName == "" {
+			return nil
+		}
+		raw := lookup(envName)
+		if raw == "" {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("environment variable %s: %w", envName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	err = walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) error {
+		flagName := field.Tag.Get("flag")
+		raw, ok := overrides[flagName]
+		if flagName == "" || !ok {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("flag -%s: %w", flagName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// walk calls fn for every leaf setting of the struct held by v.
+func walk(v reflect.Value, fn func(field reflect.StructField, value reflect.Value) error) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := walk(value, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported setting type %s", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ConsistencyTokenMetadataKey carries the time of the last write made by a
+// client session. Mutations return it in a response header, and clients send
+// it back on their following requests to read their own writes.
+const ConsistencyTokenMetadataKey = "x-consistency-token"
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads are served by the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired reports whether reads made with ctx must see the latest
+// writes, which only the primary guarantees.
+func PrimaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+	return required
+}
+
+// IssueConsistencyToken returns a token for the write just made in the
+// response header of the current RPC.
+func IssueConsistencyToken(ctx context.Context) {
+	token := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ConsistencyTokenMetadataKey, token))
+}
+
+// UnaryServerInterceptor routes the reads of a request to the primary when it
+// carries a consistency token issued less than window ago, which covers the
+// time replicas may lag behind the primary.
+func UnaryServerInterceptor(window time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(ConsistencyTokenMetadataKey); len(values) > 0 {
+				writtenAt, err := strconv.ParseInt(values[0], 10, 64)
+				if err == nil && time.Since(time.UnixMilli(writtenAt)) < window {
+					ctx = WithPrimary(ctx)
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the UnaryServerInterceptor of streaming RPCs.
+func StreamServerInterceptor(window time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+			if values := md.Get(ConsistencyTokenMetadataKey); len(values) > 0 {
+				writtenAt, err := strconv.ParseInt(values[0], 10, 64)
+				if err == nil && time.Since(time.UnixMilli(writtenAt)) < window {
+					stream = grpcutil.WithContext(stream, WithPrimary(stream.Context()))
+				}
+			}
+		}
+		return handler(srv, stream)
+	}
+}
@@ -1,15 +1,103 @@
 package database
 
-import "github.com/tittuvarghese/ss-go-core/storage"
+import (
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var log = logger.NewLogger("product-service")
 
 type RelationalDatabase struct {
-	Instance *storage.RelationalDB
+	// Instance is the primary, which serves every write.
+	Instance *RelationalDB
+	// Dialect is one of the Dialect constants, selected from the connection string.
+	Dialect string
+	dsn     string
+
+	replicas   []*replica
+	next       atomic.Uint64
+	stopChecks chan struct{}
+	checksDone chan struct{}
 }
 
 func NewRelationalDatabase(conn string) (*RelationalDatabase, error) {
-	handler, err := storage.NewRelationalDbHandler(conn)
+	dialect, dsn, err := ParseDSN(conn)
 	if err != nil {
 		return nil, err
 	}
-	return &RelationalDatabase{Instance: handler}, nil
+	return &RelationalDatabase{Dialect: dialect, dsn: dsn}, nil
+}
+
+// Open connects to the database.
+func (d *RelationalDatabase) Open() error {
+	db, err := gorm.Open(dialectorFor(d.Dialect, d.dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Warn),
+		// Unique violations surface as gorm.ErrDuplicatedKey on every dialect
+		TranslateError: true,
+	})
+	if err != nil {
+		return err
+	}
+	d.Instance = &RelationalDB{Instance: db}
+	return nil
+}
+
+// DB returns the gorm handle for queries not covered by the storage helpers.
+func (d *RelationalDatabase) DB() *gorm.DB {
+	return d.Instance.Instance
+}
+
+// SqlDB returns the connection pool backing the gorm handle.
+func (d *RelationalDatabase) SqlDB() (*sql.DB, error) {
+	return d.DB().DB()
+}
+
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// ConfigurePool applies the connection pool limits to the primary and to
+// every replica.
+func (d *RelationalDatabase) ConfigurePool(opts PoolOptions) error {
+	pools, err := d.ReplicaPools()
+	if err != nil {
+		return err
+	}
+	primary, err := d.SqlDB()
+	if err != nil {
+		return err
+	}
+	pools["primary"] = primary
+	for _, sqlDB := range pools {
+		sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(opts.ConnMaxLifetime)
+		sqlDB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	}
+	return nil
+}
+
+// Close stops the replica health checks and releases the connection pools.
+// It is safe to call on a database which was never opened.
+func (d *RelationalDatabase) Close() error {
+	if d == nil || d.Instance == nil || d.Instance.Instance == nil {
+		return nil
+	}
+	replicaErr := d.closeReplicas()
+	sqlDB, err := d.SqlDB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.Close(); err != nil {
+		return err
+	}
+	return replicaErr
 }
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// replicaPingTimeout bounds a single health check of a replica.
+const replicaPingTimeout = 2 * time.Second
+
+type replica struct {
+	name     string
+	db       *RelationalDB
+	healthy  atomic.Bool
+	failures int
+}
+
+// AddReplica connects to a read replica. Replicas must use the same dialect
+// as the primary and start out healthy.
+func (d *RelationalDatabase) AddReplica(conn string) error {
+	dialect, dsn, err := ParseDSN(conn)
+	if err != nil {
+		return err
+	}
+	if dialect != d.Dialect {
+		return fmt.Errorf("replica dialect %s does not match primary dialect %s", dialect, d.Dialect)
+	}
+	db, err := gorm.Open(dialectorFor(dialect, dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Warn),
+		// Errors read from a replica translate like those of the primary
+		TranslateError: true,
+	})
+	if err != nil {
+		return err
+	}
+	r := &replica{name: "replica-" + strconv.Itoa(len(d.replicas)), db: &RelationalDB{Instance: db}}
+	r.healthy.Store(true)
+	d.replicas = append(d.replicas, r)
+	return nil
+}
+
+// Reader returns the handle read-only queries should use: the next healthy
+// replica in round robin order, or the primary when the context requires it
+// (see WithPrimary) or no replica is healthy.
+func (d *RelationalDatabase) Reader(ctx context.Context) *RelationalDB {
+	if len(d.replicas) == 0 || PrimaryRequired(ctx) {
+		return d.Instance
+	}
+	start := d.next.Add(1)
+	for i := range d.replicas {
+		r := d.replicas[(start+uint64(i))%uint64(len(d.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return d.Instance
+}
+
+// ReplicaPools returns the connection pools of the replicas by name.
+func (d *RelationalDatabase) ReplicaPools() (map[string]*sql.DB, error) {
+	pools := make(map[string]*sql.DB, len(d.replicas))
+	for _, r := range d.replicas {
+		sqlDB, err := r.db.Instance.DB()
+		if err != nil {
+			return nil, err
+		}
+		pools[r.name] = sqlDB
+	}
+	return pools, nil
+}
+
+// StartHealthChecks pings every replica each interval. A replica failing
+// failureThreshold consecutive checks stops receiving reads until a check
+// succeeds again. The checks stop when the database is closed.
+func (d *RelationalDatabase) StartHealthChecks(interval time.Duration, failureThreshold int) {
+	if len(d.replicas) == 0 || d.stopChecks != nil {
+		return
+	}
+	d.stopChecks = make(chan struct{})
+	d.checksDone = make(chan struct{})
+	go func() {
+		defer close(d.checksDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stopChecks:
+				return
+			case <-ticker.C:
+				for _, r := range d.replicas {
+					d.checkReplica(r, failureThreshold)
+				}
+			}
+		}
+	}()
+}
+
+func (d *RelationalDatabase) checkReplica(r *replica, failureThreshold int) {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+
+	sqlDB, err := r.db.Instance.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err == nil {
+		if !r.healthy.Swap(true) {
+			log.Info("Read replica " + r.name + " is healthy again")
+		}
+		r.failures = 0
+		return
+	}
+
+	r.failures++
+	if r.failures >= failureThreshold && r.healthy.Swap(false) {
+		log.Error("Ejecting unhealthy read replica "+r.name, err)
+	}
+}
+
+func (d *RelationalDatabase) closeReplicas() error {
+	if d.stopChecks != nil {
+		close(d.stopChecks)
+		<-d.checksDone
+		d.stopChecks = nil
+	}
+	var firstErr error
+	for _, r := range d.replicas {
+		sqlDB, err := r.db.Instance.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RelationalDB provides the storage helpers used by the service layer on top
+// of a gorm handle of any supported dialect. It mirrors the MySQL only
+// storage handler of ss-go-core.
+type RelationalDB struct {
+	Instance *gorm.DB
+}
+
+// Insert creates the record held by model.
+func (r *RelationalDB) Insert(model interface{}) error {
+	return r.Instance.Create(model).Error
+}
+
+// Update saves every field of the record held by model.
+func (r *RelationalDB) Update(model interface{}) error {
+	return r.Instance.Save(model).Error
+}
+
+// QueryByCondition fills model, a pointer to a slice, with the records
+// matching every column/value pair of condition. A slice value matches any of
+// its elements. The result holds model itself, or the error is
+// gorm.ErrRecordNotFound when no record matched.
+func (r *RelationalDB) QueryByCondition(model interface{}, condition map[string]interface{}) ([]interface{}, error) {
+	query := r.Instance
+	if len(condition) > 0 {
+		query = query.Where(condition)
+	}
+	result := query.Find(model)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return []interface{}{model}, nil
+}
+
+// Transaction runs fn in a transaction, which is committed when fn returns nil
+// and rolled back otherwise.
+func (r *RelationalDB) Transaction(ctx context.Context, fn func(tx *RelationalDB) error) error {
+	return r.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&RelationalDB{Instance: tx})
+	})
+}
@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the layout of the dates of calendars and estimates.
+const DateLayout = "2006-01-02"
+
+// MaxDays bounds the business days of handling and transit, and so the days
+// AddBusinessDays walks through.
+const MaxDays = 365
+
+// Calendar tells business days from weekends and holidays. A nil Calendar
+// treats Saturday and Sunday as the only days off.
+type Calendar struct {
+	Weekend  map[time.Weekday]bool
+	Holidays map[string]bool
+}
+
+var defaultWeekend = map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}
+
+// IsBusinessDay reports whether the date of day, in its location, is a
+// business day.
+func (c *Calendar) IsBusinessDay(day time.Time) bool {
+	if c == nil {
+		return !defaultWeekend[day.Weekday()]
+	}
+	return !c.Weekend[day.Weekday()] && !c.Holidays[day.Format(DateLayout)]
+}
+
+// AddBusinessDays returns the date n business days after day, n being
+// capped at MaxDays. The date of day itself is never counted, so n zero
+// returns day.
+func (c *Calendar) AddBusinessDays(day time.Time, n int) time.Time {
+	n = min(n, MaxDays)
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if c.IsBusinessDay(day) {
+			n--
+		}
+	}
+	return day
+}
+
+// NextBusinessDay returns day when it is a business day, otherwise the first
+// business day after it.
+func (c *Calendar) NextBusinessDay(day time.Time) time.Time {
+	for !c.IsBusinessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// ReadCalendarFile reads a calendar from a YAML file of the form
+//
+//	weekend: [saturday, sunday]
+//	holidays:
+//	  - 2026-12-25
+//
+// The weekend defaults to Saturday and Sunday when omitted.
+func ReadCalendarFile(path string) (*Calendar, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	var file struct {
+		Weekend  []string `yaml:"weekend"`
+		Holidays []string `yaml:"holidays"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parsing calendar %s: %w", path, err)
+	}
+
+	calendar := &Calendar{Weekend: defaultWeekend, Holidays: map[string]bool{}}
+	if file.Weekend != nil {
+		calendar.Weekend = map[time.Weekday]bool{}
+		for _, name := range file.Weekend {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("calendar %s: unknown weekday %q", path, name)
+			}
+			calendar.Weekend[day] = true
+		}
+		if len(calendar.Weekend) == len(weekdays) {
+			return nil, fmt.Errorf("calendar %s: every day is a weekend day", path)
+		}
+	}
+	for _, holiday := range file.Holidays {
+		if _, err := time.Parse(DateLayout, holiday); err != nil {
+			return nil, fmt.Errorf("calendar %s: holiday %q is not a YYYY-MM-DD date", path, holiday)
+		}
+		calendar.Holidays[holiday] = true
+	}
+	return calendar, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
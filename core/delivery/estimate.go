@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnknownZone = errors.New("unknown delivery zone")
+
+// Handling describes how a seller dispatches orders: the handling of orders
+// placed on a business day before Cutoff, local time, starts that day, the
+// handling of later orders the next business day. Orders are dispatched Days
+// business days after handling starts.
+type Handling struct {
+	Days int
+	// Cutoff is the offset from midnight orders must be placed before.
+	Cutoff   time.Duration
+	Location *time.Location
+	Calendar *Calendar
+}
+
+// Transit is the number of business days carriers take to deliver to a zone,
+// counted in the calendar of the zone.
+type Transit struct {
+	MinDays  int
+	MaxDays  int
+	Location *time.Location
+	Calendar *Calendar
+}
+
+// Estimate holds the dates of a delivery. ShipDate is a date of the seller
+// location, the delivery dates are dates of the zone location.
+type Estimate struct {
+	ShipDate time.Time
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// Estimator estimates delivery dates from the handling of each seller and the
+// transit times of each zone.
+type Estimator struct {
+	// Default applies to the sellers without a handling of their own. Its
+	// Days only apply to products without handling days.
+	Default Handling
+	Sellers map[string]Handling
+	Zones   map[string]Transit
+}
+
+// Zone returns the transit times of the zone of the given name.
+func (e Estimator) Zone(name string) (Transit, error) {
+	transit, ok := e.Zones[name]
+	if !ok {
+		return Transit{}, fmt.Errorf("%w %q", ErrUnknownZone, name)
+	}
+	return transit, nil
+}
+
+// Estimate returns the dates an order placed at orderedAt of a product of a
+// seller, dispatched in handlingDays business days, is shipped and delivered
+// on. The handling of the seller, when configured, overrides the handling days
+// of the product.
+func (e Estimator) Estimate(sellerId string, handlingDays int, transit Transit, orderedAt time.Time) Estimate {
+	handling, ok := e.Sellers[sellerId]
+	if !ok {
+		handling = e.Default
+		if handlingDays > 0 {
+			handling.Days = handlingDays
+		}
+	}
+
+	local := orderedAt.In(handling.Location)
+	day := date(local, handling.Location)
+	if local.Sub(day) >= handling.Cutoff {
+		day = day.AddDate(0, 0, 1)
+	}
+	day = handling.Calendar.NextBusinessDay(day)
+	ship := handling.Calendar.AddBusinessDays(day, handling.Days)
+
+	// Carriers pick the parcel up on the ship date of the seller, whatever
+	// the date is at the destination
+	start := date(ship, transit.Location)
+	return Estimate{
+		ShipDate: ship,
+		Earliest: transit.Calendar.AddBusinessDays(start, transit.MinDays),
+		Latest:   transit.Calendar.AddBusinessDays(start, transit.MaxDays),
+	}
+}
+
+// date returns midnight of the calendar date of t, in location.
+func date(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
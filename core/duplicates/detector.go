@@ -0,0 +1,214 @@
+package duplicates
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// Modes of the duplicate check on product creation
+const (
+	ModeOff   = "off"
+	ModeWarn  = "warn"
+	ModeBlock = "block"
+)
+
+// Detector finds near-duplicate products: products of the same category
+// whose names have a similarity of at least Threshold and whose prices and
+// dimensions differ by at most PriceTolerance and SizeTolerance, relative to
+// the larger value. Prices in different currencies never match; dimensions
+// unknown on either product are ignored.
+type Detector struct {
+	Threshold      float64
+	PriceTolerance float64
+	SizeTolerance  float64
+}
+
+// Match is a product found to duplicate another one.
+type Match struct {
+	Product    models.Product
+	Similarity float64
+}
+
+// Cluster is a group of products duplicating each other, directly or through
+// other products of the cluster.
+type Cluster struct {
+	Products []models.Product
+	// Pairs compares every pair of Products.
+	Pairs []Pair
+}
+
+// Pair compares two products of a cluster, by their index in Products.
+// Duplicate reports whether they duplicate each other directly.
+type Pair struct {
+	A, B       int
+	Similarity float64
+	Duplicate  bool
+}
+
+// Best returns the highest similarity of the product at index i with another
+// product of the cluster.
+func (c Cluster) Best(i int) float64 {
+	best := 0.0
+	for _, pair := range c.Pairs {
+		if (pair.A == i || pair.B == i) && pair.Similarity > best {
+			best = pair.Similarity
+		}
+	}
+	return best
+}
+
+type entry struct {
+	product   models.Product
+	signature Signature
+}
+
+// Matches returns the candidates duplicating product, most similar first.
+func (d Detector) Matches(product models.Product, candidates []models.Product) []Match {
+	target := entry{product, Sign(product.Name)}
+	var matches []Match
+	for _, candidate := range candidates {
+		if candidate.ID == product.ID {
+			continue
+		}
+		if similarity, ok := d.similar(target, entry{candidate, Sign(candidate.Name)}); ok {
+			matches = append(matches, Match{Product: candidate, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	return matches
+}
+
+// Pairs compares every pair of products.
+func (d Detector) Pairs(products []models.Product) []Pair {
+	entries := make([]entry, len(products))
+	for i, product := range products {
+		entries[i] = entry{product, Sign(product.Name)}
+	}
+	return d.pairs(entries)
+}
+
+func (d Detector) pairs(entries []entry) []Pair {
+	var pairs []Pair
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			similarity, ok := d.similar(entries[i], entries[j])
+			pairs = append(pairs, Pair{A: i, B: j, Similarity: similarity, Duplicate: ok})
+		}
+	}
+	return pairs
+}
+
+// Cluster groups the products into clusters of duplicates. Only clusters of
+// two products or more are returned, largest clusters first, each with the
+// comparison of every pair of its products.
+func (d Detector) Cluster(products []models.Product) []Cluster {
+	entries := make([]entry, len(products))
+	for i, product := range products {
+		entries[i] = entry{product, Sign(product.Name)}
+	}
+
+	// Products sharing a band of their signature in the same category are
+	// candidates, verified before joining their clusters
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	type bucketKey struct {
+		category string
+		band     int
+		values   [rows]uint64
+	}
+	buckets := map[bucketKey][]int{}
+	for i, e := range entries {
+		if e.signature.empty() {
+			continue
+		}
+		category := strings.ToLower(e.product.Category)
+		for b := 0; b < bands; b++ {
+			key := bucketKey{category, b, e.signature.band(b)}
+			for _, j := range buckets[key] {
+				if find(i) == find(j) {
+					continue
+				}
+				if _, ok := d.similar(entries[i], entries[j]); ok {
+					parent[find(i)] = find(j)
+				}
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range entries {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+	var clusters []Cluster
+	for _, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		cluster := make([]entry, len(indexes))
+		for k, i := range indexes {
+			cluster[k] = entries[i]
+		}
+		products := make([]models.Product, len(cluster))
+		for k, e := range cluster {
+			products[k] = e.product
+		}
+		clusters = append(clusters, Cluster{Products: products, Pairs: d.pairs(cluster)})
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Products) != len(clusters[j].Products) {
+			return len(clusters[i].Products) > len(clusters[j].Products)
+		}
+		return clusters[i].Products[0].ID.String() < clusters[j].Products[0].ID.String()
+	})
+	return clusters
+}
+
+// similar reports whether two products are duplicates, along with the
+// similarity of their names.
+func (d Detector) similar(a, b entry) (float64, bool) {
+	if !strings.EqualFold(a.product.Category, b.product.Category) {
+		return 0, false
+	}
+	similarity := a.signature.Similarity(b.signature)
+	if similarity < d.Threshold {
+		return similarity, false
+	}
+	if a.product.Price.Currency != b.product.Price.Currency ||
+		!within(float64(a.product.Price.Amount), float64(b.product.Price.Amount), d.PriceTolerance) {
+		return similarity, false
+	}
+	for _, size := range [][2]float64{
+		{a.product.Width, b.product.Width},
+		{a.product.Height, b.product.Height},
+		{a.product.Depth, b.product.Depth},
+		{a.product.Weight, b.product.Weight},
+	} {
+		if size[0] > 0 && size[1] > 0 && !within(size[0], size[1], d.SizeTolerance) {
+			return similarity, false
+		}
+	}
+	return similarity, true
+}
+
+// within reports whether a and b differ by at most tolerance relative to the
+// larger of both.
+func within(a, b, tolerance float64) bool {
+	larger := math.Max(math.Abs(a), math.Abs(b))
+	return larger == 0 || math.Abs(a-b) <= tolerance*larger
+}
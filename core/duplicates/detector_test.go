@@ -0,0 +1,133 @@
+package duplicates
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+var detector = Detector{Threshold: 0.8, PriceTolerance: 0.1, SizeTolerance: 0.1}
+
+func listing(name string, category string, price int64) models.Product {
+	return models.Product{
+		ID:       uuid.New(),
+		Name:     name,
+		Category: category,
+		Price:    money.Money{Currency: "USD", Amount: price},
+		Width:    20,
+		Weight:   1.5,
+	}
+}
+
+func TestMatches(t *testing.T) {
+	product := listing("Stainless Steel Electric Kettle", "kitchen", 2999)
+
+	exact := listing("stainless steel electric kettle", "Kitchen", 2999)
+	cheaper := listing("Stainless steel electric kettle!", "kitchen", 2800)
+	unsized := listing("Stainless Steel Electric Kettle", "kitchen", 2999)
+	unsized.Width, unsized.Weight = 0, 0
+
+	tooCheap := listing("Stainless Steel Electric Kettle", "kitchen", 2500)
+	euros := listing("Stainless Steel Electric Kettle", "kitchen", 2999)
+	euros.Price.Currency = "EUR"
+	larger := listing("Stainless Steel Electric Kettle", "kitchen", 2999)
+	larger.Width = 25
+	otherCategory := listing("Stainless Steel Electric Kettle", "outdoor", 2999)
+	otherName := listing("Cast iron stovetop kettle", "kitchen", 2999)
+
+	matches := detector.Matches(product, []models.Product{
+		product, tooCheap, cheaper, euros, larger, otherCategory, otherName, unsized, exact,
+	})
+	want := map[uuid.UUID]bool{exact.ID: true, cheaper.ID: true, unsized.ID: true}
+	if len(matches) != len(want) {
+		t.Fatalf("Matches returned %d products, want %d", len(matches), len(want))
+	}
+	for i, match := range matches {
+		if !want[match.Product.ID] {
+			t.Errorf("Matches returned %q at %v", match.Product.Name, match.Product.Price)
+		}
+		if i > 0 && match.Similarity > matches[i-1].Similarity {
+			t.Errorf("match %d is more similar than match %d", i, i-1)
+		}
+	}
+	if matches[0].Similarity != 1 {
+		t.Errorf("best similarity = %v, want 1", matches[0].Similarity)
+	}
+}
+
+func TestShortNamesAreNoDuplicates(t *testing.T) {
+	for _, name := range []string{"", "TV", "Pen", "***"} {
+		a, b := listing(name, "office", 500), listing(name, "office", 500)
+		if matches := detector.Matches(a, []models.Product{b}); len(matches) != 0 {
+			t.Errorf("Matches(%q) = %+v, want none", name, matches)
+		}
+		if clusters := detector.Cluster([]models.Product{a, b}); len(clusters) != 0 {
+			t.Errorf("Cluster(%q) = %d clusters, want none", name, len(clusters))
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	products := []models.Product{
+		listing("Wireless Gaming Mouse", "electronics", 4999),
+		listing("Stainless Steel Electric Kettle", "kitchen", 2999),
+		listing("Wireless gaming mouse", "electronics", 4999),
+		listing("Stainless steel electric kettle", "kitchen", 2899),
+		listing("Stainless steel electric kettle", "Kitchen", 2999),
+		listing("Wireless Gaming Mouse", "toys", 4999),
+		listing("Ergonomic office chair", "furniture", 15999),
+	}
+	clusters := detector.Cluster(products)
+	if len(clusters) != 2 {
+		t.Fatalf("Cluster returned %d clusters, want 2", len(clusters))
+	}
+
+	// Largest clusters first
+	kettles, mice := clusters[0], clusters[1]
+	if len(kettles.Products) != 3 || len(mice.Products) != 2 {
+		t.Fatalf("clusters of %d and %d products, want 3 and 2", len(kettles.Products), len(mice.Products))
+	}
+	for _, product := range kettles.Products {
+		if product.Category != "kitchen" && product.Category != "Kitchen" {
+			t.Errorf("kettle cluster holds %q in %s", product.Name, product.Category)
+		}
+	}
+	for _, product := range mice.Products {
+		if product.Category != "electronics" {
+			t.Errorf("mouse cluster holds %q in %s", product.Name, product.Category)
+		}
+	}
+	if len(kettles.Pairs) != 3 || len(mice.Pairs) != 1 {
+		t.Errorf("%d and %d pairs, want 3 and 1", len(kettles.Pairs), len(mice.Pairs))
+	}
+	for i := range kettles.Products {
+		if best := kettles.Best(i); best != 1 {
+			t.Errorf("best similarity of kettle %d = %v, want 1", i, best)
+		}
+	}
+}
+
+func TestClusterJoinsThroughOtherProducts(t *testing.T) {
+	// The cheapest and dearest differ by more than the price tolerance but
+	// both duplicate the one in between
+	products := []models.Product{
+		listing("Stainless Steel Electric Kettle", "kitchen", 2500),
+		listing("Stainless Steel Electric Kettle", "kitchen", 3000),
+		listing("Stainless Steel Electric Kettle", "kitchen", 2750),
+	}
+	clusters := detector.Cluster(products)
+	if len(clusters) != 1 || len(clusters[0].Products) != 3 {
+		t.Fatalf("Cluster = %+v, want a cluster of 3 products", clusters)
+	}
+	direct := 0
+	for _, pair := range clusters[0].Pairs {
+		if pair.Duplicate {
+			direct++
+		}
+	}
+	if direct != 2 {
+		t.Errorf("%d direct duplicates, want 2", direct)
+	}
+}
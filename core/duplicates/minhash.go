@@ -0,0 +1,121 @@
+package duplicates
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// Signatures hold numHashes MinHash values, compared in bands of rows values
+// to find candidate pairs without comparing every pair of products.
+const (
+	numHashes = 64
+	bands     = 16
+	rows      = numHashes / bands
+
+	shingleSize = 3
+)
+
+// Signature is the MinHash signature of a product name.
+type Signature [numHashes]uint64
+
+var seeds = func() [numHashes]uint64 {
+	var seeds [numHashes]uint64
+	state := uint64(0x2545f4914f6cdd1d)
+	for i := range seeds {
+		state = mix(state + uint64(i))
+		seeds[i] = state
+	}
+	return seeds
+}()
+
+// Normalize lowercases a name and reduces it to its letters and digits,
+// separated by single spaces, so punctuation and spacing do not matter.
+func Normalize(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Shingles returns the character shingles of the normalized name. Names of a
+// single shingle or less have none, as they are too short to tell products
+// apart.
+func Shingles(name string) []string {
+	runes := []rune(Normalize(name))
+	if len(runes) <= shingleSize {
+		return nil
+	}
+	seen := map[string]bool{}
+	var shingles []string
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		shingle := string(runes[i : i+shingleSize])
+		if !seen[shingle] {
+			seen[shingle] = true
+			shingles = append(shingles, shingle)
+		}
+	}
+	return shingles
+}
+
+// Sign computes the MinHash signature of a name. Names without shingles get
+// an empty signature.
+func Sign(name string) Signature {
+	var signature Signature
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for _, shingle := range Shingles(name) {
+		hash := fnv.New64a()
+		hash.Write([]byte(shingle))
+		base := hash.Sum64()
+		for i, seed := range seeds {
+			if value := mix(base ^ seed); value < signature[i] {
+				signature[i] = value
+			}
+		}
+	}
+	return signature
+}
+
+// Similarity estimates the Jaccard similarity of the shingles of two names.
+// Empty signatures are similar to none.
+func (s Signature) Similarity(other Signature) float64 {
+	if s.empty() || other.empty() {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / numHashes
+}
+
+// empty reports whether the signature is of a name without shingles.
+func (s Signature) empty() bool {
+	for _, value := range s {
+		if value != ^uint64(0) {
+			return false
+		}
+	}
+	return true
+}
+
+// band returns a key of the values of band b of the signature.
+func (s Signature) band(b int) [rows]uint64 {
+	var key [rows]uint64
+	copy(key[:], s[b*rows:(b+1)*rows])
+	return key
+}
+
+// mix is the finalizer of splitmix64, spreading the bits of x.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
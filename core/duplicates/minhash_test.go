@@ -0,0 +1,59 @@
+package duplicates
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for name, want := range map[string]string{
+		"Stainless-Steel  Kettle (1.7L)": "stainless steel kettle 1 7l",
+		"  Café crème ":                  "café crème",
+		"!!!":                            "",
+	} {
+		if normalized := Normalize(name); normalized != want {
+			t.Errorf("Normalize(%q) = %q, want %q", name, normalized, want)
+		}
+	}
+}
+
+func TestShingles(t *testing.T) {
+	for name, want := range map[string][]string{
+		"Kettle": {"ket", "ett", "ttl", "tle"},
+		"a-b-c":  {"a b", " b ", "b c"},
+		// Shingles are listed once
+		"aaaaa": {"aaa"},
+		"Cafés": {"caf", "afé", "fés"},
+		// Names of a single shingle or less have none
+		"Pen": nil,
+		"TV":  nil,
+		"":    nil,
+		"?!":  nil,
+	} {
+		if shingles := Shingles(name); !reflect.DeepEqual(shingles, want) {
+			t.Errorf("Shingles(%q) = %q, want %q", name, shingles, want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Steel Kettle", "steel-KETTLE!", 1, 1},
+		{"Stainless steel kettle 1.7L", "Stainless steel kettle 1.8L", 0.7, 0.95},
+		{"Stainless steel kettle", "Wireless gaming mouse", 0, 0.2},
+		// Short and empty names are similar to none, themselves included
+		{"TV", "TV", 0, 0},
+		{"Pen", "pen", 0, 0},
+		{"", "", 0, 0},
+		{"!!", "??", 0, 0},
+		{"", "Steel kettle", 0, 0},
+	} {
+		similarity := Sign(test.a).Similarity(Sign(test.b))
+		if similarity < test.min || similarity > test.max {
+			t.Errorf("Similarity(%q, %q) = %v, want between %v and %v", test.a, test.b, similarity, test.min, test.max)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package events publishes what happens in the service to other services,
+// such as the notifications sent to sellers.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+)
+
+// Types of events
+const (
+	// TypeQuestionAsked notifies a seller of a question about one of their
+	// products. Its subject is the seller id and its payload a QuestionAsked.
+	TypeQuestionAsked = "seller.question_asked"
+)
+
+// Event is published at least once: consumers deduplicate events by ID.
+type Event struct {
+	ID      string
+	Type    string
+	Subject string
+	// Payload is the JSON encoded event.
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// QuestionAsked is the payload of TypeQuestionAsked events.
+type QuestionAsked struct {
+	QuestionId  string    `json:"question_id"`
+	ProductId   string    `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	SellerId    string    `json:"seller_id"`
+	AuthorName  string    `json:"author_name"`
+	Body        string    `json:"body"`
+	AskedAt     time.Time `json:"asked_at"`
+}
+
+// Publisher delivers events, in order.
+type Publisher interface {
+	Publish(ctx context.Context, events []Event) error
+}
+
+// LogPublisher writes the events to the log, for local development or until
+// consumers exist.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, events []Event) error {
+	logger := logging.FromContext(ctx)
+	for _, event := range events {
+		logger.Info("event published",
+			"event_id", event.ID,
+			"type", event.Type,
+			"subject", event.Subject,
+			"payload", string(event.Payload),
+		)
+	}
+	return nil
+}
+
+// RedisPublisher appends the events to a Redis stream, trimmed to about
+// MaxLen entries. Entries hold the fields of the event: id, type, subject,
+// payload and created_at.
+type RedisPublisher struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+func NewRedisPublisher(client redis.UniversalClient, stream string, maxLen int64) *RedisPublisher {
+	return &RedisPublisher{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (p *RedisPublisher) Publish(ctx context.Context, events []Event) error {
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, event := range events {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: p.stream,
+				MaxLen: p.maxLen,
+				Approx: true,
+				Values: map[string]interface{}{
+					"id":         event.ID,
+					"type":       event.Type,
+					"subject":    event.Subject,
+					"payload":    event.Payload,
+					"created_at": event.CreatedAt.UTC().Format(time.RFC3339Nano),
+				},
+			})
+		}
+		return nil
+	})
+	return err
+}
+
+// Close releases the connections to the Redis server.
+func (p *RedisPublisher) Close(ctx context.Context) error {
+	return p.client.Close()
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+)
+
+var log = logger.NewLogger("product-service")
+
+// OutboxLoader reads up to limit events waiting in the outbox, oldest first.
+type OutboxLoader func(ctx context.Context, limit int) ([]Event, error)
+
+// OutboxDeleter removes published events from the outbox.
+type OutboxDeleter func(ctx context.Context, ids []string) error
+
+// RelayOptions configures a Relay.
+type RelayOptions struct {
+	Interval  time.Duration
+	BatchSize int
+	Publisher Publisher
+	Load      OutboxLoader
+	Delete    OutboxDeleter
+}
+
+// Relay publishes the events recorded in the outbox every Interval, and right
+// away when notified of new ones. Events are deleted once published, so an
+// event is published again when the deletion fails or several replicas relay
+// it at once. A nil *Relay publishes nothing.
+type Relay struct {
+	opts   RelayOptions
+	notify chan struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRelay starts relaying the events until Close is called.
+func NewRelay(opts RelayOptions) *Relay {
+	r := &Relay{
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Notify wakes the relay up after events were recorded. It never blocks.
+func (r *Relay) Notify() {
+	if r == nil {
+		return
+	}
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops relaying once the events being published are.
+func (r *Relay) Close(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		r.relay()
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		case <-r.notify:
+		}
+	}
+}
+
+// relay publishes batches of events until the outbox is empty or an error
+// occurs, leaving the remaining events to the next round.
+func (r *Relay) relay() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Interval)
+	defer cancel()
+	for {
+		events, err := r.opts.Load(ctx, r.opts.BatchSize)
+		if err != nil {
+			log.Error("Error loading events from the outbox", err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+		if err := r.opts.Publisher.Publish(ctx, events); err != nil {
+			log.Error("Error publishing events", err)
+			return
+		}
+		ids := make([]string, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		if err := r.opts.Delete(ctx, ids); err != nil {
+			log.Error("Error deleting published events from the outbox", err)
+			return
+		}
+		if len(events) < r.opts.BatchSize {
+			return
+		}
+	}
+}
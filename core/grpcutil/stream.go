@@ -0,0 +1,23 @@
+// Package grpcutil holds the helpers shared by the gRPC interceptors.
+package grpcutil
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// WithContext returns stream with its context replaced by ctx, for stream
+// interceptors which pass values on to the handler.
+func WithContext(stream grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &serverStream{ServerStream: stream, ctx: ctx}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
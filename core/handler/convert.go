@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/delivery"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// productToProto converts a stored product into its API representation. The
+// product is returned even when its image urls cannot be decoded.
+func productToProto(product models.Product) (*proto.Product, error) {
+	response := &proto.Product{
+		ProductId:             product.ID.String(),
+		Name:                  product.Name,
+		Quantity:              product.Quantity,
+		Type:                  product.Type,
+		Category:              product.Category,
+		Price:                 moneyToProto(product.Price),
+		Size:                  &proto.Product_Size{Width: product.Width, Height: product.Height, Depth: product.Depth},
+		Weight:                product.Weight,
+		ShippingBasePrice:     moneyToProto(product.ShippingBasePrice),
+		BaseDeliveryTimelines: product.BaseDeliveryTimelines,
+		SellerId:              product.SellerId.String(),
+		Status:                product.Status,
+		StatusReason:          product.StatusReason,
+	}
+
+	if product.Sale != nil {
+		response.OriginalPrice = moneyToProto(product.Sale.OriginalPrice)
+		response.SaleEndsAt = timestamppb.New(product.Sale.EndsAt)
+	}
+	for _, tier := range product.PriceTiers {
+		response.PriceTiers = append(response.PriceTiers, &proto.PriceTier{
+			MinQuantity: tier.MinQuantity,
+			UnitPrice:   moneyToProto(tier.UnitPrice),
+		})
+	}
+
+	err := json.Unmarshal([]byte(product.ImageUrls), &response.ImageUrls)
+	return response, err
+}
+
+func moneyToProto(amount money.Money) *proto.Money {
+	units, nanos := amount.Units()
+	return &proto.Money{CurrencyCode: amount.Currency, Units: units, Nanos: nanos}
+}
+
+// moneyFromProto converts an amount of the API, rejecting unknown currencies
+// and amounts more precise than the minor unit of their currency.
+func moneyFromProto(amount *proto.Money) (money.Money, error) {
+	if amount == nil {
+		return money.Money{}, fmt.Errorf("amount is required")
+	}
+	return money.FromUnits(amount.GetCurrencyCode(), amount.GetUnits(), amount.GetNanos())
+}
+
+// checkPrices validates the prices of a product before it is stored.
+func checkPrices(product models.Product) error {
+	if product.Price.Amount < 0 {
+		return fmt.Errorf("price: must not be negative")
+	}
+	if product.ShippingBasePrice.Amount < 0 {
+		return fmt.Errorf("shipping_base_price: must not be negative")
+	}
+	if product.ShippingBasePrice.Currency != product.Price.Currency {
+		return fmt.Errorf("shipping_base_price: currency %s differs from the price currency %s",
+			product.ShippingBasePrice.Currency, product.Price.Currency)
+	}
+	return nil
+}
+
+// checkDeliveryTimelines validates the base delivery timelines of a product,
+// in business days.
+func checkDeliveryTimelines(days int32) error {
+	if days < 0 || days > delivery.MaxDays {
+		return fmt.Errorf("base_delivery_timelines: must be between 0 and %d business days", delivery.MaxDays)
+	}
+	return nil
+}
+
+// revisionToProto converts a recorded product revision into its API
+// representation.
+func revisionToProto(revision models.ProductRevision) (*proto.ProductRevision, error) {
+	product, err := revision.Product()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := revision.FieldChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	// The image urls of the snapshot were validated when it was recorded
+	snapshot, _ := productToProto(product)
+	response := &proto.ProductRevision{
+		Revision:  revision.Revision,
+		ProductId: revision.ProductId.String(),
+		Actor:     revision.Actor,
+		Source:    revision.Source,
+		CreatedAt: timestamppb.New(revision.CreatedAt),
+		Snapshot:  snapshot,
+	}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, &proto.FieldChange{
+			Field:    change.Field,
+			OldValue: change.Old,
+			NewValue: change.New,
+		})
+	}
+	return response, nil
+}
+
+func scheduleToProto(schedule models.PriceSchedule) *proto.PriceSchedule {
+	response := &proto.PriceSchedule{
+		ScheduleId: schedule.ID.String(),
+		Name:       schedule.Name,
+		TargetType: schedule.TargetType,
+		TargetId:   schedule.TargetId,
+		Kind:       schedule.Kind,
+		PercentOff: schedule.PercentOff,
+		StartAt:    timestamppb.New(schedule.StartAt),
+		EndAt:      timestamppb.New(schedule.EndAt),
+		Priority:   schedule.Priority,
+		CreatedBy:  schedule.CreatedBy,
+		CreatedAt:  timestamppb.New(schedule.CreatedAt),
+	}
+	if schedule.Amount.Currency != "" {
+		response.Amount = moneyToProto(schedule.Amount)
+	}
+	return response
+}
+
+// scheduleFromProto converts a price schedule of the API. The amount is only
+// read for the kinds which use it.
+func scheduleFromProto(schedule *proto.PriceSchedule) (models.PriceSchedule, error) {
+	if schedule == nil {
+		return models.PriceSchedule{}, fmt.Errorf("schedule is required")
+	}
+	if err := schedule.GetStartAt().CheckValid(); err != nil {
+		return models.PriceSchedule{}, fmt.Errorf("start_at: %w", err)
+	}
+	if err := schedule.GetEndAt().CheckValid(); err != nil {
+		return models.PriceSchedule{}, fmt.Errorf("end_at: %w", err)
+	}
+
+	result := models.PriceSchedule{
+		Name:       schedule.GetName(),
+		TargetType: schedule.GetTargetType(),
+		TargetId:   schedule.GetTargetId(),
+		Kind:       schedule.GetKind(),
+		PercentOff: schedule.GetPercentOff(),
+		StartAt:    schedule.GetStartAt().AsTime(),
+		EndAt:      schedule.GetEndAt().AsTime(),
+		Priority:   schedule.GetPriority(),
+	}
+	if schedule.GetKind() != pricing.KindPercentOff {
+		amount, err := moneyFromProto(schedule.GetAmount())
+		if err != nil {
+			return models.PriceSchedule{}, fmt.Errorf("amount: %w", err)
+		}
+		result.Amount = amount
+	}
+	return result, nil
+}
+
+func reviewToProto(review models.Review) *proto.Review {
+	return &proto.Review{
+		ReviewId:         review.ID.String(),
+		ProductId:        review.ProductId.String(),
+		CustomerId:       review.CustomerId.String(),
+		AuthorName:       review.AuthorName,
+		Rating:           review.Rating,
+		Title:            review.Title,
+		Body:             review.Body,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Status:           review.Status,
+		StatusReason:     review.StatusReason,
+		CreatedAt:        timestamppb.New(review.CreatedAt),
+	}
+}
+
+func ratingToProto(rating models.ProductRating) *proto.ProductRating {
+	return &proto.ProductRating{
+		Average:     rating.AverageRating,
+		ReviewCount: rating.ReviewCount,
+		Histogram:   rating.Histogram(),
+	}
+}
+
+func questionToProto(question models.Question) *proto.Question {
+	result := &proto.Question{
+		QuestionId:   question.ID.String(),
+		ProductId:    question.ProductId.String(),
+		CustomerId:   question.CustomerId.String(),
+		AuthorName:   question.AuthorName,
+		Body:         question.Body,
+		Upvotes:      question.Upvotes,
+		Status:       question.Status,
+		StatusReason: question.StatusReason,
+		CreatedAt:    timestamppb.New(question.CreatedAt),
+	}
+	for _, answer := range question.Answers {
+		result.Answers = append(result.Answers, answerToProto(answer))
+	}
+	return result
+}
+
+func answerToProto(answer models.Answer) *proto.Answer {
+	return &proto.Answer{
+		AnswerId:     answer.ID.String(),
+		QuestionId:   answer.QuestionId.String(),
+		AuthorId:     answer.AuthorId.String(),
+		AuthorName:   answer.AuthorName,
+		AuthorRole:   answer.AuthorRole,
+		Body:         answer.Body,
+		Upvotes:      answer.Upvotes,
+		Status:       answer.Status,
+		StatusReason: answer.StatusReason,
+		CreatedAt:    timestamppb.New(answer.CreatedAt),
+	}
+}
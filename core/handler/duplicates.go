@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/duplicates"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sellerDuplicates finds the listings of the seller of product it duplicates,
+// most similar first. It returns an AlreadyExists error in block mode.
+func (s *Server) sellerDuplicates(ctx context.Context, product models.Product) ([]*proto.DuplicateProduct, error) {
+	if s.DuplicateMode == "" || s.DuplicateMode == duplicates.ModeOff {
+		return nil, nil
+	}
+	candidates, err := service.GetSellerCategoryProducts(ctx, product.SellerId.String(), product.Category, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	matches := s.Duplicates.Matches(product, candidates)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	if s.DuplicateMode == duplicates.ModeBlock {
+		ids := make([]string, len(matches))
+		for i, match := range matches {
+			ids[i] = match.Product.ID.String()
+		}
+		return nil, status.Errorf(codes.AlreadyExists, "product duplicates listings %s of the seller", strings.Join(ids, ", "))
+	}
+	return duplicatesToProto(matches), nil
+}
+
+func (s *Server) FindDuplicates(ctx context.Context, req *proto.FindDuplicatesRequest) (*proto.FindDuplicatesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.FindDuplicates", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.category", req.GetCategory()),
+	))
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	var clusters []duplicates.Cluster
+	if req.GetProductId() != "" {
+		products, err := service.GetProductsByIds(ctx, []string{req.GetProductId()}, s.RdbInstance)
+		if err != nil {
+			return &proto.FindDuplicatesResponse{Message: "Failed to find duplicates. error: " + err.Error()}, err
+		}
+		if len(products) == 0 {
+			return &proto.FindDuplicatesResponse{Message: "Product not found"}, status.Error(codes.NotFound, "product not found")
+		}
+		catalog, err := service.GetCatalog(ctx, products[0].Category, s.RdbInstance)
+		if err != nil {
+			return &proto.FindDuplicatesResponse{Message: "Failed to find duplicates. error: " + err.Error()}, err
+		}
+		if matches := s.Duplicates.Matches(products[0], catalog); len(matches) > 0 {
+			cluster := duplicates.Cluster{Products: products[:1]}
+			for _, match := range matches {
+				cluster.Products = append(cluster.Products, match.Product)
+			}
+			cluster.Pairs = s.Duplicates.Pairs(cluster.Products)
+			clusters = append(clusters, cluster)
+		}
+	} else {
+		// Clustering is bounded to a category, the whole catalog is left to
+		// the duplicates batch job
+		if strings.TrimSpace(req.GetCategory()) == "" {
+			return &proto.FindDuplicatesResponse{
+				Message: "Invalid request",
+			}, status.Error(codes.InvalidArgument, "category: is required without product_id")
+		}
+		catalog, err := service.GetCatalog(ctx, req.GetCategory(), s.RdbInstance)
+		if err != nil {
+			return &proto.FindDuplicatesResponse{Message: "Failed to find duplicates. error: " + err.Error()}, err
+		}
+		clusters = s.Duplicates.Cluster(catalog)
+	}
+
+	response := &proto.FindDuplicatesResponse{Message: "Successfully searched for duplicates"}
+	for _, cluster := range clusters {
+		response.Clusters = append(response.Clusters, clusterToProto(cluster))
+	}
+	return response, nil
+}
+
+func clusterToProto(cluster duplicates.Cluster) *proto.DuplicateCluster {
+	response := &proto.DuplicateCluster{}
+	for i, product := range cluster.Products {
+		response.Products = append(response.Products, duplicatesToProto([]duplicates.Match{{Product: product, Similarity: cluster.Best(i)}})...)
+	}
+	for _, pair := range cluster.Pairs {
+		response.Pairs = append(response.Pairs, &proto.DuplicatePair{
+			ProductId:      cluster.Products[pair.A].ID.String(),
+			OtherProductId: cluster.Products[pair.B].ID.String(),
+			Similarity:     pair.Similarity,
+			Duplicate:      pair.Duplicate,
+		})
+	}
+	return response
+}
+
+func duplicatesToProto(matches []duplicates.Match) []*proto.DuplicateProduct {
+	response := make([]*proto.DuplicateProduct, len(matches))
+	for i, match := range matches {
+		response[i] = &proto.DuplicateProduct{
+			ProductId:  match.Product.ID.String(),
+			SellerId:   match.Product.SellerId.String(),
+			Name:       match.Product.Name,
+			Category:   match.Product.Category,
+			Status:     match.Product.Status,
+			Similarity: match.Similarity,
+		}
+	}
+	return response
+}
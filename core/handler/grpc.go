@@ -3,43 +3,140 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/tittuvarghese/ss-go-core/logger"
+	"github.com/tittuvarghese/ss-go-product-service/core/blob"
+	"github.com/tittuvarghese/ss-go-product-service/core/cache"
 	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/delivery"
+	"github.com/tittuvarghese/ss-go-product-service/core/duplicates"
+	"github.com/tittuvarghese/ss-go-product-service/core/events"
+	"github.com/tittuvarghese/ss-go-product-service/core/images"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/moderation"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"github.com/tittuvarghese/ss-go-product-service/core/shipping"
 	"github.com/tittuvarghese/ss-go-product-service/models"
 	"github.com/tittuvarghese/ss-go-product-service/proto"
 	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"net"
 )
 
 type Server struct {
 	proto.UnimplementedProductServiceServer
-	GrpcServer  *grpc.Server
-	RdbInstance *database.RelationalDatabase
+	GrpcServer       *grpc.Server
+	HealthServer     *health.Server
+	RdbInstance      *database.RelationalDatabase
+	ProductCache     *cache.ProductCache
+	EnableReflection bool
+	// Rounding turns converted prices into the price points of a currency.
+	Rounding pricing.Rounding
+	// Schedules are the price schedules applied to product reads.
+	Schedules *pricing.Schedules
+	// Admins may call the admin RPCs.
+	Admins security.Role
+	// SellerAgents authenticate sellers, so the seller_id of their requests
+	// is trusted to change the products of a seller, to read its listings
+	// which are not active, and their answers by the seller are badged as
+	// such.
+	SellerAgents security.Role
+	// Moderators may approve, reject and suspend products, and moderate
+	// reviews, questions and answers.
+	Moderators security.Role
+	// PurchaseVerifiers may flag reviews as verified purchases.
+	PurchaseVerifiers security.Role
+	// ReviewApproval holds new reviews for a moderator instead of publishing
+	// them right away.
+	ReviewApproval bool
+	// QuestionApproval holds new questions and answers for a moderator
+	// instead of publishing them right away.
+	QuestionApproval bool
+	// ModerationRules decide on products on create, update and submission,
+	// nil when disabled.
+	ModerationRules *moderation.Rules
+	// Duplicates finds near-duplicate listings. DuplicateMode decides what
+	// happens to new products duplicating a listing of their seller.
+	Duplicates    duplicates.Detector
+	DuplicateMode string
+	// Shipping holds the zone rate tables of shipping quotes.
+	Shipping shipping.Rates
+	// Delivery estimates delivery dates.
+	Delivery delivery.Estimator
+	// Blobs stores the content of product images, ImageLimits bounds them.
+	Blobs       blob.BlobStore
+	ImageLimits images.Limits
+	// Renditions renders resized copies of uploaded images in the background
+	Renditions *images.Pipeline
+	// Events publishes the events recorded in the outbox, such as the
+	// questions notified to sellers.
+	Events *events.Relay
 }
 
 var log = logger.NewLogger("product-service")
 
-func NewGrpcServer() *Server {
-	return &Server{GrpcServer: grpc.NewServer()}
+var tracer = otel.Tracer("github.com/tittuvarghese/ss-go-product-service/handler")
+
+func NewGrpcServer(opts ...grpc.ServerOption) *Server {
+	return &Server{GrpcServer: grpc.NewServer(opts...), HealthServer: health.NewServer()}
 }
 
-func (s *Server) Run(port string) {
+// Run blocks serving gRPC requests until the server is stopped. It returns nil
+// when the server was stopped through Shutdown.
+func (s *Server) Run(port string) error {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		log.Error("Failed to listen", err)
+		return err
 	}
 
 	proto.RegisterProductServiceServer(s.GrpcServer, s)
+	healthpb.RegisterHealthServer(s.GrpcServer, s.HealthServer)
+	s.HealthServer.SetServingStatus(proto.ProductService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
 
 	// Register reflection service on gRPC server
-	reflection.Register(s.GrpcServer)
+	if s.EnableReflection {
+		reflection.Register(s.GrpcServer)
+	}
 	log.Info("GRPC server is listening on port " + port)
 	if err := s.GrpcServer.Serve(lis); err != nil {
 		log.Error("failed to serve", err)
+		return err
+	}
+	return nil
+}
+
+// Shutdown marks the server as NOT_SERVING so load balancers stop routing new
+// requests to it, then waits for in-flight RPCs to complete. Once the context
+// expires the remaining connections are closed forcefully.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.HealthServer.Shutdown()
+
+	drained := make(chan struct{})
+	go func() {
+		s.GrpcServer.GracefulStop()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.GrpcServer.Stop()
+		<-drained
+		return ctx.Err()
 	}
 }
 
@@ -48,20 +145,51 @@ func (s *Server) mustEmbedUnimplementedAuthServiceServer() {
 }
 
 func (s *Server) CreateProduct(ctx context.Context, req *proto.CreateProductRequest) (*proto.CreateProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.CreateProduct", trace.WithAttributes(
+		attribute.String("product.seller_id", req.GetProduct().GetSellerId()),
+	))
+	defer span.End()
+
 	var product models.Product
 
 	product.Name = req.Product.Name
 	product.Quantity = req.Product.Quantity
 	product.Type = req.Product.Type
 	product.Category = req.Product.Category
-	//product.ImageUrls = req.Product.ImageUrls
-	product.Price = req.Product.Price
 	product.Width = req.Product.Size.Width
 	product.Height = req.Product.Size.Height
+	product.Depth = req.Product.Size.Depth
 	product.Weight = req.Product.Weight
-	product.ShippingBasePrice = req.Product.ShippingBasePrice
 	product.BaseDeliveryTimelines = req.Product.BaseDeliveryTimelines
 
+	price, err := moneyFromProto(req.Product.Price)
+	if err != nil {
+		return &proto.CreateProductResponse{
+			Message: "Invalid price. error: " + err.Error(),
+		}, status.Errorf(codes.InvalidArgument, "price: %v", err)
+	}
+	product.Price = price
+	// The shipping base price is optional and free by default
+	product.ShippingBasePrice = money.Money{Currency: price.Currency}
+	if req.Product.ShippingBasePrice != nil {
+		product.ShippingBasePrice, err = moneyFromProto(req.Product.ShippingBasePrice)
+		if err != nil {
+			return &proto.CreateProductResponse{
+				Message: "Invalid shipping base price. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "shipping_base_price: %v", err)
+		}
+	}
+	if err := checkPrices(product); err != nil {
+		return &proto.CreateProductResponse{
+			Message: "Invalid price. error: " + err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := checkDeliveryTimelines(product.BaseDeliveryTimelines); err != nil {
+		return &proto.CreateProductResponse{
+			Message: "Invalid delivery timelines. error: " + err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	sellerId, err := uuid.Parse(req.Product.SellerId)
 	if err != nil {
 		return &proto.CreateProductResponse{
@@ -73,104 +201,222 @@ func (s *Server) CreateProduct(ctx context.Context, req *proto.CreateProductRequ
 	// Image Parsing
 	imageUrlsJson, err := json.Marshal(req.Product.ImageUrls)
 	if err != nil {
-		log.Error("Error marshaling data: %v", err)
+		logging.FromContext(ctx).Error("error marshaling image urls", "error", err)
 	}
 	product.ImageUrls = string(imageUrlsJson)
 
-	err = service.CreateProduct(product, s.RdbInstance)
+	duplicateListings, err := s.sellerDuplicates(ctx, product)
+	if err != nil {
+		return &proto.CreateProductResponse{
+			Message: "Failed to create the product. error: " + err.Error(),
+		}, err
+	}
+
+	outcome, err := s.moderate(&product)
+	if err != nil {
+		return &proto.CreateProductResponse{
+			Message: "Failed to moderate the product. error: " + err.Error(),
+		}, err
+	}
+	// Without moderation rules new listings go live once a moderator
+	// approves them
+	if product.Status == "" {
+		product.Status = models.ProductStatusDraft
+	}
+
+	created, err := service.CreateProduct(ctx, product, s.auditFor(ctx, req), s.RdbInstance)
 	if err != nil {
 		return &proto.CreateProductResponse{
 			Message: "Failed to create the product. error: " + err.Error(),
 		}, err
 	}
+	s.afterWrite(ctx)
 
 	// Return the created product
-	return &proto.CreateProductResponse{Message: "Successfully created the product listing"}, nil
+	return &proto.CreateProductResponse{
+		Message:    "Successfully created the product listing",
+		ProductId:  created.ID.String(),
+		Status:     created.Status,
+		Moderation: outcome,
+		Duplicates: duplicateListings,
+	}, nil
 }
 
 func (s *Server) GetProduct(ctx context.Context, req *proto.GetProductRequest) (*proto.GetProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.GetProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
 
-	productResult, err := service.GetProduct(req.GetProductId(), s.RdbInstance)
-
-	fmt.Println(err)
-
+	product, found, err := s.productCache(ctx).Get(ctx, req.GetProductId(), s.loadProducts)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(productResult) <= 0 {
-		log.Error("no products found", nil)
+	if !found || !s.viewable(ctx, product, req.GetSellerId()) {
+		logging.FromContext(ctx).Warn("no products found", "product_id", req.GetProductId())
 		return &proto.GetProductResponse{
 			Message: "No products found",
 		}, fmt.Errorf("no products found")
 	}
 
-	product := productResult[0]
+	products := []models.Product{product}
+	if req.GetIncludePriceTiers() {
+		products, err = service.LoadPriceTiers(ctx, products, req.GetCustomerGroup(), s.RdbInstance)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	response := &proto.Product{
-		ProductId:             product.ID.String(),
-		Name:                  product.Name,
-		Quantity:              product.Quantity,
-		Type:                  product.Type,
-		Category:              product.Category,
-		Price:                 product.Price,
-		Size:                  &proto.Product_Size{Width: product.Width, Height: product.Height},
-		Weight:                product.Weight,
-		ShippingBasePrice:     product.ShippingBasePrice,
-		BaseDeliveryTimelines: product.BaseDeliveryTimelines,
-		SellerId:              product.SellerId.String(),
+	localized, err := s.effectivePrices(ctx, products, req.GetCurrency())
+	if err != nil {
+		return nil, err
 	}
+	product = localized[0]
 
-	err = json.Unmarshal([]byte(product.ImageUrls), &response.ImageUrls)
+	response, err := productToProto(product)
 	if err != nil {
-		log.Error("Error unmarshalling JSON: %v", err)
+		logging.FromContext(ctx).Error("error unmarshalling image urls", "product_id", product.ID.String(), "error", err)
 		return &proto.GetProductResponse{
 			Message: "No products found",
 		}, err
 	}
+	if err := s.loadImages(ctx, []*proto.Product{response}); err != nil {
+		return nil, err
+	}
+	if err := s.loadRatings(ctx, []*proto.Product{response}); err != nil {
+		return nil, err
+	}
 
 	return &proto.GetProductResponse{Message: "Successfully retrieved the product", Product: response}, nil
 }
 
 func (s *Server) GetProducts(ctx context.Context, req *proto.GetProductsRequest) (*proto.GetProductsResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.GetProducts")
+	defer span.End()
+
+	var products []models.Product
+	if len(req.GetQuery()) > 0 {
+		// Batch lookup by product ids, served from the cache when possible
+		found, err := s.productCache(ctx).GetMany(ctx, req.GetQuery(), s.loadProducts)
+		if err != nil {
+			return nil, err
+		}
+		for _, productId := range req.GetQuery() {
+			if product, ok := found[productId]; ok && visible(product, "") {
+				products = append(products, product)
+			}
+		}
+		if len(products) == 0 {
+			return nil, fmt.Errorf("products not found")
+		}
+	} else {
+		if sort := req.GetSort(); sort != "" && sort != service.SortByRating && sort != service.SortByReviewCount {
+			return nil, status.Errorf(codes.InvalidArgument, "sort: must be %s or %s", service.SortByRating, service.SortByReviewCount)
+		}
+		all, err := service.GetProducts(ctx, req.GetSort(), s.RdbInstance)
+		if err != nil {
+			return nil, err
+		}
+		products = *all
+	}
 
-	products, err := service.GetProducts(s.RdbInstance)
+	products, err := s.effectivePrices(ctx, products, req.GetCurrency())
 	if err != nil {
 		return nil, err
 	}
-	var response []*proto.Product
 
-	for _, product := range *products {
-		res := &proto.Product{
-			ProductId:             product.ID.String(),
-			Name:                  product.Name,
-			Quantity:              product.Quantity,
-			Type:                  product.Type,
-			Category:              product.Category,
-			Price:                 product.Price,
-			Size:                  &proto.Product_Size{Width: product.Width, Height: product.Height},
-			Weight:                product.Weight,
-			ShippingBasePrice:     product.ShippingBasePrice,
-			BaseDeliveryTimelines: product.BaseDeliveryTimelines,
-			SellerId:              product.SellerId.String(),
-		}
-		err = json.Unmarshal([]byte(product.ImageUrls), &res.ImageUrls)
+	var response []*proto.Product
+	for _, product := range products {
+		res, err := productToProto(product)
 		if err != nil {
-			log.Error("Error unmarshalling JSON: %v", err)
+			logging.FromContext(ctx).Error("error unmarshalling image urls", "product_id", product.ID.String(), "error", err)
 		}
 		response = append(response, res)
 	}
+	if err := s.loadImages(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := s.loadRatings(ctx, response); err != nil {
+		return nil, err
+	}
 	return &proto.GetProductsResponse{Message: "Successfully retrieved the product", Products: response}, nil
 }
 
-func (s *Server) UpdateProduct(ctx context.Context, req *proto.UpdateProductRequest) (*proto.UpdateProductResponse, error) {
-
-	productResult, err := service.GetProduct(req.GetProductId(), s.RdbInstance)
+// loadProducts reads products from the database on a cache miss.
+func (s *Server) loadProducts(ctx context.Context, ids []string) (map[string]models.Product, error) {
+	products, err := service.GetProductsByIds(ctx, ids, s.RdbInstance)
 	if err != nil {
 		return nil, err
 	}
+	found := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		found[product.ID.String()] = product
+	}
+	return found, nil
+}
+
+// visible reports whether a product may be returned to the caller: active
+// products to everyone, the others to their seller only.
+func visible(product models.Product, sellerId string) bool {
+	return product.Status == models.ProductStatusActive || (sellerId != "" && product.SellerId.String() == sellerId)
+}
+
+// viewable reports whether the caller may read a product which is not active:
+// moderators always, its seller through a seller agent, which authenticated
+// the seller_id of the request.
+func (s *Server) viewable(ctx context.Context, product models.Product, sellerId string) bool {
+	if visible(product, "") || s.Moderators.Check(ctx) == nil {
+		return true
+	}
+	return s.SellerAgents.Check(ctx) == nil && visible(product, sellerId)
+}
+
+// productCache returns the cache for the reads of ctx. Requests which must
+// read their own writes bypass it, as it may briefly hold what a lagging
+// replica returned.
+func (s *Server) productCache(ctx context.Context) *cache.ProductCache {
+	if database.PrimaryRequired(ctx) {
+		return nil
+	}
+	return s.ProductCache
+}
+
+// primaryProduct reads a product from the primary, failing with NOT_FOUND
+// when it does not exist.
+func (s *Server) primaryProduct(ctx context.Context, productId string) (models.Product, error) {
+	productResult, err := service.GetProduct(database.WithPrimary(ctx), productId, s.RdbInstance)
+	if errors.Is(err, service.ErrProductNotFound) || (err == nil && len(productResult) == 0) {
+		return models.Product{}, status.Error(codes.NotFound, "product not found")
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+	return productResult[0], nil
+}
+
+// afterWrite must follow every product mutation. It drops the changed
+// products from the cache and hands the caller a consistency token, so its
+// next reads are served by the primary.
+func (s *Server) afterWrite(ctx context.Context, productIds ...string) {
+	s.ProductCache.Invalidate(ctx, productIds...)
+	database.IssueConsistencyToken(ctx)
+}
 
-	product := productResult[0]
+func (s *Server) UpdateProduct(ctx context.Context, req *proto.UpdateProductRequest) (*proto.UpdateProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.UpdateProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetProduct().GetSellerId()),
+	))
+	defer span.End()
+
+	// The product is written back as a whole, so it must not be read from a
+	// lagging replica
+	ctx = database.WithPrimary(ctx)
+	product, err := s.primaryProduct(ctx, req.GetProductId())
+	if err != nil {
+		return nil, err
+	}
 
 	if product.SellerId.String() != req.Product.SellerId {
 		return &proto.UpdateProductResponse{
@@ -190,8 +436,13 @@ func (s *Server) UpdateProduct(ctx context.Context, req *proto.UpdateProductRequ
 	if req.Product.Category != "" {
 		product.Category = req.Product.Category
 	}
-	if req.Product.Price > 0 {
-		product.Price = req.Product.Price
+	if req.Product.Price != nil {
+		product.Price, err = moneyFromProto(req.Product.Price)
+		if err != nil {
+			return &proto.UpdateProductResponse{
+				Message: "Invalid price. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "price: %v", err)
+		}
 	}
 
 	if req.Product.GetSize() != nil && req.Product.Size.Width > 0 {
@@ -200,10 +451,28 @@ func (s *Server) UpdateProduct(ctx context.Context, req *proto.UpdateProductRequ
 	if req.Product.GetSize() != nil && req.Product.Size.Height > 0 {
 		product.Height = req.Product.Size.Height
 	}
-	if req.Product.ShippingBasePrice > 0 {
-		product.ShippingBasePrice = req.Product.ShippingBasePrice
+	if req.Product.GetSize() != nil && req.Product.Size.Depth > 0 {
+		product.Depth = req.Product.Size.Depth
 	}
-	if req.Product.BaseDeliveryTimelines > 0 {
+	if req.Product.ShippingBasePrice != nil {
+		product.ShippingBasePrice, err = moneyFromProto(req.Product.ShippingBasePrice)
+		if err != nil {
+			return &proto.UpdateProductResponse{
+				Message: "Invalid shipping base price. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "shipping_base_price: %v", err)
+		}
+	}
+	if err := checkPrices(product); err != nil {
+		return &proto.UpdateProductResponse{
+			Message: "Invalid price. error: " + err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Product.BaseDeliveryTimelines != 0 {
+		if err := checkDeliveryTimelines(req.Product.BaseDeliveryTimelines); err != nil {
+			return &proto.UpdateProductResponse{
+				Message: "Invalid delivery timelines. error: " + err.Error(),
+			}, status.Error(codes.InvalidArgument, err.Error())
+		}
 		product.BaseDeliveryTimelines = req.Product.BaseDeliveryTimelines
 	}
 
@@ -211,19 +480,31 @@ func (s *Server) UpdateProduct(ctx context.Context, req *proto.UpdateProductRequ
 		// Image Parsing
 		imageUrlsJson, err := json.Marshal(req.Product.ImageUrls)
 		if err != nil {
-			log.Error("Error marshaling data: %v", err)
+			logging.FromContext(ctx).Error("error marshaling image urls", "error", err)
 		}
 		product.ImageUrls = string(imageUrlsJson)
 	}
 
-	err = service.UpdateProduct(product, s.RdbInstance)
+	outcome, err := s.moderate(&product)
+	if err != nil {
+		return &proto.UpdateProductResponse{
+			Message: "Failed to moderate the product. error: " + err.Error(),
+		}, err
+	}
+
+	err = service.UpdateProduct(ctx, product, s.auditFor(ctx, req), s.RdbInstance)
 	if err != nil {
 		return &proto.UpdateProductResponse{
 			Message: "Failed to update the product. error: " + err.Error(),
 		}, err
 	}
+	s.afterWrite(ctx, product.ID.String())
 
 	// Return the created product
-	return &proto.UpdateProductResponse{Message: "Successfully updated the product listing"}, nil
+	return &proto.UpdateProductResponse{
+		Message:    "Successfully updated the product listing",
+		Status:     product.Status,
+		Moderation: outcome,
+	}, nil
 
 }
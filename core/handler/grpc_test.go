@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/cache"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerStream records the headers a handler sets on its RPC.
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return "/ecommerce.ProductService/UpdateProduct" }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *headerStream) SetTrailer(md metadata.MD) error { return nil }
+
+func TestAfterWriteInvalidatesTheCache(t *testing.T) {
+	stream := &headerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	s := &Server{ProductCache: cache.NewProductCache(cache.ProductCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})}
+
+	id := uuid.New()
+	name := "kettle"
+	loads := 0
+	load := func(ctx context.Context, ids []string) (map[string]models.Product, error) {
+		loads++
+		return map[string]models.Product{id.String(): {ID: id, Name: name}}, nil
+	}
+	s.productCache(ctx).Get(ctx, id.String(), load)
+
+	name = "electric kettle"
+	s.afterWrite(ctx, id.String())
+	product, _, err := s.productCache(ctx).Get(ctx, id.String(), load)
+	if err != nil || product.Name != "electric kettle" || loads != 2 {
+		t.Errorf("Get after afterWrite = %q, %v after %d loads, want the new name", product.Name, err, loads)
+	}
+	if tokens := stream.header.Get(database.ConsistencyTokenMetadataKey); len(tokens) != 1 {
+		t.Errorf("consistency tokens = %v, want one", tokens)
+	}
+
+	// Requests which read their own writes bypass the cache
+	if s.productCache(database.WithPrimary(ctx)) != nil {
+		t.Error("productCache is used by a request which requires the primary")
+	}
+}
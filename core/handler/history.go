@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 500
+)
+
+// auditFor describes the caller and RPC of a mutation for the product history.
+func (s *Server) auditFor(ctx context.Context, req interface{}) service.Audit {
+	method, _ := grpc.Method(ctx)
+	return service.Audit{Actor: security.Subject(ctx, req, s.SellerAgents), Source: method}
+}
+
+func (s *Server) GetProductHistory(ctx context.Context, req *proto.GetProductHistoryRequest) (*proto.GetProductHistoryResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.GetProductHistory", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	if err := s.checkViewable(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.GetProductHistoryResponse{Message: "No products found"}, err
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	pageSize = min(pageSize, maxHistoryPageSize)
+
+	revisions, err := service.GetProductHistory(ctx, req.GetProductId(), pageSize, req.GetBeforeRevision(), s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.GetProductHistoryResponse{Message: "Successfully retrieved the product history"}
+	for _, revision := range revisions {
+		res, err := revisionToProto(revision)
+		if err != nil {
+			logging.FromContext(ctx).Error("error decoding product revision", "product_id", req.GetProductId(), "revision", revision.Revision, "error", err)
+			return nil, err
+		}
+		response.Revisions = append(response.Revisions, res)
+	}
+	if len(revisions) == pageSize {
+		if last := revisions[len(revisions)-1].Revision; last > 1 {
+			response.NextBeforeRevision = last
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) GetProductAsOf(ctx context.Context, req *proto.GetProductAsOfRequest) (*proto.GetProductAsOfResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.GetProductAsOf", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	if err := req.GetAsOf().CheckValid(); err != nil {
+		return nil, fmt.Errorf("invalid as_of: %w", err)
+	}
+	if err := s.checkViewable(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.GetProductAsOfResponse{Message: "No products found"}, err
+	}
+
+	revision, err := service.GetProductRevisionAt(ctx, req.GetProductId(), req.GetAsOf().AsTime(), s.RdbInstance)
+	if err != nil {
+		return &proto.GetProductAsOfResponse{Message: "No products found"}, err
+	}
+	product, err := revision.Product()
+	if err != nil {
+		return nil, err
+	}
+	response, err := productToProto(product)
+	if err != nil {
+		logging.FromContext(ctx).Error("error unmarshalling image urls", "product_id", product.ID.String(), "error", err)
+	}
+	return &proto.GetProductAsOfResponse{
+		Message:  "Successfully retrieved the product",
+		Product:  response,
+		Revision: revision.Revision,
+	}, nil
+}
+
+// checkViewable fails with NOT_FOUND unless the caller may read the product,
+// see viewable.
+func (s *Server) checkViewable(ctx context.Context, productId string, sellerId string) error {
+	product, found, err := s.productCache(ctx).Get(ctx, productId, s.loadProducts)
+	if err != nil {
+		return err
+	}
+	if !found || !s.viewable(ctx, product, sellerId) {
+		return status.Error(codes.NotFound, "product not found")
+	}
+	return nil
+}
+
+func (s *Server) RevertProduct(ctx context.Context, req *proto.RevertProductRequest) (*proto.RevertProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.RevertProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+	))
+	defer span.End()
+
+	ctx = database.WithPrimary(ctx)
+	if _, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.RevertProductResponse{Message: "Failed to revert the product. error: " + err.Error()}, err
+	}
+
+	// The restored content is moderated again, as it may differ from what was
+	// last approved
+	var outcome *proto.ModerationResult
+	var productStatus string
+	revision, err := service.RevertProduct(ctx, req.GetProductId(), req.GetRevision(), func(product *models.Product) error {
+		var err error
+		outcome, err = s.moderate(product)
+		productStatus = product.Status
+		return err
+	}, s.auditFor(ctx, req), s.RdbInstance)
+	if err != nil {
+		return &proto.RevertProductResponse{
+			Message: "Failed to revert the product. error: " + err.Error(),
+		}, err
+	}
+	s.afterWrite(ctx, req.GetProductId())
+
+	return &proto.RevertProductResponse{
+		Message:    fmt.Sprintf("Successfully reverted the product to revision %d", req.GetRevision()),
+		Revision:   revision.Revision,
+		Status:     productStatus,
+		Moderation: outcome,
+	}, nil
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/images"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxAltTextLength matches the size of the alt_text column.
+const maxAltTextLength = 250
+
+func (s *Server) UploadProductImage(stream grpc.ClientStreamingServer[proto.UploadProductImageRequest, proto.UploadProductImageResponse]) error {
+	ctx, span := tracer.Start(stream.Context(), "Server.UploadProductImage")
+	defer span.End()
+
+	if s.Blobs == nil {
+		return status.Error(codes.Unimplemented, "image storage is not configured")
+	}
+	first, err := stream.Recv()
+	if err == io.EOF || (err == nil && first.GetMetadata() == nil) {
+		return status.Error(codes.InvalidArgument, "metadata: must be the first message of the stream")
+	}
+	if err != nil {
+		return err
+	}
+	metadata := first.GetMetadata()
+	span.SetAttributes(
+		attribute.String("product.id", metadata.GetProductId()),
+		attribute.String("product.seller_id", metadata.GetSellerId()),
+	)
+	if err := checkAltText(metadata.GetAltText()); err != nil {
+		return err
+	}
+
+	product, err := s.sellerProduct(ctx, metadata.GetProductId(), metadata.GetSellerId())
+	if err != nil {
+		return err
+	}
+	if product.Status == models.ProductStatusDiscontinued {
+		return status.Error(codes.FailedPrecondition, "product is discontinued")
+	}
+
+	content, err := receiveImage(stream, s.ImageLimits.MaxSize)
+	if err != nil {
+		return err
+	}
+	// Metadata such as the location a photo was taken at is stripped before
+	// the content is stored
+	info, content, err := images.Prepare(content)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	span.SetAttributes(attribute.String("image.content_type", info.ContentType), attribute.Int64("image.size", info.Size))
+
+	image := models.ProductImage{
+		ID:          uuid.New(),
+		ProductId:   product.ID,
+		IsPrimary:   metadata.GetPrimary(),
+		AltText:     metadata.GetAltText(),
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		Width:       int32(info.Width),
+		Height:      int32(info.Height),
+		Checksum:    info.Checksum,
+		Orientation: int32(info.Orientation),
+	}
+	image.StorageKey = images.Key(product.ID.String(), image.ID.String(), info.ContentType)
+
+	// The content is stored first, an image is never recorded without it
+	if err := s.Blobs.Put(ctx, image.StorageKey, content, info.ContentType); err != nil {
+		return err
+	}
+	image, err = service.AddProductImage(ctx, image, s.ImageLimits.MaxPerProduct, s.RdbInstance)
+	if err != nil {
+		s.deleteBlob(ctx, image.StorageKey)
+		return imageError(err)
+	}
+	s.afterWrite(ctx, product.ID.String())
+	s.Renditions.Enqueue(image)
+
+	return stream.SendAndClose(&proto.UploadProductImageResponse{
+		Message: "Successfully uploaded the image",
+		Image:   s.imageToProto(image),
+	})
+}
+
+func (s *Server) UpdateProductImage(ctx context.Context, req *proto.UpdateProductImageRequest) (*proto.UpdateProductImageResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.UpdateProductImage", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+		attribute.String("image.id", req.GetImageId()),
+	))
+	defer span.End()
+
+	if err := checkAltText(req.GetAltText()); err != nil {
+		return &proto.UpdateProductImageResponse{Message: "Invalid alt text. error: " + err.Error()}, err
+	}
+	if _, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.UpdateProductImageResponse{Message: "Failed to update the image. error: " + err.Error()}, err
+	}
+	image, err := service.UpdateProductImage(ctx, req.GetProductId(), req.GetImageId(), req.GetAltText(), req.GetPrimary(), s.RdbInstance)
+	if err != nil {
+		err = imageError(err)
+		return &proto.UpdateProductImageResponse{Message: "Failed to update the image. error: " + err.Error()}, err
+	}
+	s.afterWrite(ctx, req.GetProductId())
+
+	return &proto.UpdateProductImageResponse{Message: "Successfully updated the image", Image: s.imageToProto(image)}, nil
+}
+
+func (s *Server) ReorderProductImages(ctx context.Context, req *proto.ReorderProductImagesRequest) (*proto.ReorderProductImagesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ReorderProductImages", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+	))
+	defer span.End()
+
+	if _, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.ReorderProductImagesResponse{Message: "Failed to reorder the images. error: " + err.Error()}, err
+	}
+	if err := service.ReorderProductImages(ctx, req.GetProductId(), req.GetImageIds(), s.RdbInstance); err != nil {
+		err = imageError(err)
+		return &proto.ReorderProductImagesResponse{Message: "Failed to reorder the images. error: " + err.Error()}, err
+	}
+	s.afterWrite(ctx, req.GetProductId())
+
+	stored, err := service.GetProductImages(ctx, []string{req.GetProductId()}, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	response := &proto.ReorderProductImagesResponse{Message: "Successfully reordered the images"}
+	for _, image := range stored[req.GetProductId()] {
+		response.Images = append(response.Images, s.imageToProto(image))
+	}
+	return response, nil
+}
+
+func (s *Server) DeleteProductImage(ctx context.Context, req *proto.DeleteProductImageRequest) (*proto.DeleteProductImageResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.DeleteProductImage", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+		attribute.String("image.id", req.GetImageId()),
+	))
+	defer span.End()
+
+	if _, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.DeleteProductImageResponse{Message: "Failed to delete the image. error: " + err.Error()}, err
+	}
+	image, err := service.DeleteProductImage(ctx, req.GetProductId(), req.GetImageId(), s.RdbInstance)
+	if err != nil {
+		err = imageError(err)
+		return &proto.DeleteProductImageResponse{Message: "Failed to delete the image. error: " + err.Error()}, err
+	}
+	s.afterWrite(ctx, req.GetProductId())
+	s.deleteBlob(ctx, image.StorageKey)
+	for _, rendition := range image.Renditions {
+		s.deleteBlob(ctx, rendition.StorageKey)
+	}
+
+	return &proto.DeleteProductImageResponse{Message: "Successfully deleted the image"}, nil
+}
+
+// receiveImage reads the content of an image from the chunks of the stream.
+func receiveImage(stream grpc.ClientStreamingServer[proto.UploadProductImageRequest, proto.UploadProductImageResponse], maxSize int64) ([]byte, error) {
+	var content bytes.Buffer
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return content.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if req.GetMetadata() != nil {
+			return nil, status.Error(codes.InvalidArgument, "metadata: must only be sent once, first")
+		}
+		if int64(content.Len()+len(req.GetChunk())) > maxSize {
+			return nil, status.Errorf(codes.InvalidArgument, "%v, the limit is %d bytes", images.ErrTooLarge, maxSize)
+		}
+		content.Write(req.GetChunk())
+	}
+}
+
+// loadImages sets the images of each product on its API representation.
+func (s *Server) loadImages(ctx context.Context, products []*proto.Product) error {
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.GetProductId()
+	}
+	stored, err := service.GetProductImages(ctx, ids, s.RdbInstance)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		for _, image := range stored[product.GetProductId()] {
+			product.Images = append(product.Images, s.imageToProto(image))
+		}
+	}
+	return nil
+}
+
+func (s *Server) imageToProto(image models.ProductImage) *proto.ProductImage {
+	response := &proto.ProductImage{
+		ImageId:     image.ID.String(),
+		ContentType: image.ContentType,
+		Size:        image.Size,
+		Width:       image.Width,
+		Height:      image.Height,
+		Checksum:    image.Checksum,
+		AltText:     image.AltText,
+		Position:    image.Position,
+		Primary:     image.IsPrimary,
+	}
+	for _, rendition := range image.Renditions {
+		response.Renditions = append(response.Renditions, &proto.ImageRendition{
+			Name:        rendition.Name,
+			ContentType: rendition.ContentType,
+			Size:        rendition.Size,
+			Width:       rendition.Width,
+			Height:      rendition.Height,
+		})
+	}
+	if s.Blobs != nil {
+		response.Url = s.Blobs.URL(image.StorageKey)
+		for i, rendition := range image.Renditions {
+			response.Renditions[i].Url = s.Blobs.URL(rendition.StorageKey)
+		}
+	}
+	return response
+}
+
+// deleteBlob removes the content of an image no longer recorded. A failure
+// leaves an orphaned blob behind, which is only logged.
+func (s *Server) deleteBlob(ctx context.Context, key string) {
+	if err := s.Blobs.Delete(ctx, key); err != nil {
+		logging.FromContext(ctx).Error("error deleting image content", "key", key, "error", err)
+	}
+}
+
+func checkAltText(altText string) error {
+	if utf8.RuneCountInString(altText) > maxAltTextLength {
+		return status.Errorf(codes.InvalidArgument, "alt_text: must not exceed %d characters", maxAltTextLength)
+	}
+	return nil
+}
+
+func imageError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrImageNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrDuplicateImage):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrTooManyImages):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrImageOrder):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
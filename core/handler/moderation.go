@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/moderation"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+)
+
+// moderate runs the moderation rules against the product, records the hits
+// on it and applies the decision to its status. It returns nil when the rules
+// are disabled.
+func (s *Server) moderate(product *models.Product) (*proto.ModerationResult, error) {
+	if s.ModerationRules == nil {
+		return nil, nil
+	}
+	result := s.ModerationRules.Evaluate(*product)
+	if result.Hits == nil {
+		result.Hits = []models.RuleHit{}
+	}
+	hits, err := json.Marshal(result.Hits)
+	if err != nil {
+		return nil, err
+	}
+	product.ModerationScore = int32(result.Score)
+	product.ModerationHits = string(hits)
+	if next := result.Status(product.Status); next != product.Status {
+		product.Status = next
+		product.StatusReason = result.Reason()
+	}
+	return moderationToProto(result), nil
+}
+
+func moderationToProto(result moderation.Result) *proto.ModerationResult {
+	response := &proto.ModerationResult{Decision: result.Decision, Score: int32(result.Score)}
+	for _, hit := range result.Hits {
+		response.Hits = append(response.Hits, &proto.RuleHit{
+			Rule:  hit.Rule,
+			Field: hit.Field,
+			Match: hit.Match,
+			Score: int32(hit.Score),
+		})
+	}
+	return response
+}
@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// effectivePrices returns the products with the prices buyers pay: with the
+// price schedules in effect applied in the currency of each product, then in
+// the requested currency, if any. Products without an exchange rate to the
+// requested currency keep their own.
+func (s *Server) effectivePrices(ctx context.Context, products []models.Product, currency string) ([]models.Product, error) {
+	if currency != "" {
+		if _, err := money.MinorUnits(currency); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "currency: %v", err)
+		}
+	}
+	products = s.Schedules.Apply(products)
+	if currency == "" {
+		return products, nil
+	}
+	return service.LocalizePrices(ctx, products, currency, s.Rounding, s.RdbInstance)
+}
+
+func (s *Server) SetProductPrices(ctx context.Context, req *proto.SetProductPricesRequest) (*proto.SetProductPricesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.SetProductPrices", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+	))
+	defer span.End()
+
+	ctx = database.WithPrimary(ctx)
+	product, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId())
+	if err != nil {
+		return &proto.SetProductPricesResponse{Message: "Failed to set the product prices. error: " + err.Error()}, err
+	}
+
+	var prices []models.ProductPrice
+	seen := map[string]bool{}
+	for i, amount := range req.GetPrices() {
+		price, err := moneyFromProto(amount)
+		if err == nil && price.Amount < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		if err == nil && price.Currency == product.Price.Currency {
+			err = fmt.Errorf("%s is the currency of the product, update its price instead", price.Currency)
+		}
+		if err == nil && seen[price.Currency] {
+			err = fmt.Errorf("%s is listed twice", price.Currency)
+		}
+		if err != nil {
+			return &proto.SetProductPricesResponse{
+				Message: "Invalid price. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "prices[%d]: %v", i, err)
+		}
+		seen[price.Currency] = true
+		prices = append(prices, models.ProductPrice{ProductId: product.ID, Currency: price.Currency, Amount: price.Amount})
+	}
+
+	err = service.SetProductPrices(ctx, req.GetProductId(), prices, s.RdbInstance)
+	if err != nil {
+		return &proto.SetProductPricesResponse{
+			Message: "Failed to set the product prices. error: " + err.Error(),
+		}, err
+	}
+	s.afterWrite(ctx)
+
+	return &proto.SetProductPricesResponse{Message: "Successfully set the product prices"}, nil
+}
+
+func (s *Server) SetFxRates(ctx context.Context, req *proto.SetFxRatesRequest) (*proto.SetFxRatesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.SetFxRates")
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	var rates []pricing.Rate
+	for i, rate := range req.GetRates() {
+		fx := pricing.Rate{Base: rate.GetBaseCurrency(), Quote: rate.GetQuoteCurrency(), Rate: rate.GetRate()}
+		if err := fx.Validate(); err != nil {
+			return &proto.SetFxRatesResponse{
+				Message: "Invalid exchange rate. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "rates[%d]: %v", i, err)
+		}
+		rates = append(rates, fx)
+	}
+
+	if err := service.SetFxRates(ctx, rates, s.RdbInstance); err != nil {
+		return &proto.SetFxRatesResponse{
+			Message: "Failed to store the exchange rates. error: " + err.Error(),
+		}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.SetFxRatesResponse{Message: fmt.Sprintf("Successfully stored %d exchange rates", len(rates))}, nil
+}
+
+func (s *Server) ListFxRates(ctx context.Context, req *proto.ListFxRatesRequest) (*proto.ListFxRatesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ListFxRates")
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	rates, err := service.GetFxRates(ctx, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	response := &proto.ListFxRatesResponse{Message: "Successfully retrieved the exchange rates"}
+	for _, rate := range rates {
+		response.Rates = append(response.Rates, &proto.FxRate{
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			Rate:          rate.Rate,
+			UpdatedAt:     timestamppb.New(rate.UpdatedAt),
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) CreatePriceSchedule(ctx context.Context, req *proto.CreatePriceScheduleRequest) (*proto.CreatePriceScheduleResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.CreatePriceSchedule", trace.WithAttributes(
+		attribute.String("schedule.target_type", req.GetSchedule().GetTargetType()),
+		attribute.String("schedule.target_id", req.GetSchedule().GetTargetId()),
+	))
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	schedule, err := scheduleFromProto(req.GetSchedule())
+	if err == nil {
+		err = pricing.ValidateSchedule(schedule)
+	}
+	if err != nil {
+		return &proto.CreatePriceScheduleResponse{
+			Message: "Invalid price schedule. error: " + err.Error(),
+		}, status.Errorf(codes.InvalidArgument, "schedule: %v", err)
+	}
+	schedule.CreatedBy = s.auditFor(ctx, req).Actor
+
+	schedule, err = service.CreatePriceSchedule(ctx, schedule, s.RdbInstance)
+	if err != nil {
+		return &proto.CreatePriceScheduleResponse{
+			Message: "Failed to create the price schedule. error: " + err.Error(),
+		}, err
+	}
+	s.refreshSchedules(ctx)
+
+	return &proto.CreatePriceScheduleResponse{
+		Message:  "Successfully created the price schedule",
+		Schedule: scheduleToProto(schedule),
+	}, nil
+}
+
+func (s *Server) ListPriceSchedules(ctx context.Context, req *proto.ListPriceSchedulesRequest) (*proto.ListPriceSchedulesResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ListPriceSchedules")
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	schedules, err := service.GetPriceSchedules(ctx, time.Now(), s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	response := &proto.ListPriceSchedulesResponse{Message: "Successfully retrieved the price schedules"}
+	for _, schedule := range schedules {
+		response.Schedules = append(response.Schedules, scheduleToProto(schedule))
+	}
+	return response, nil
+}
+
+func (s *Server) DeletePriceSchedule(ctx context.Context, req *proto.DeletePriceScheduleRequest) (*proto.DeletePriceScheduleResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.DeletePriceSchedule", trace.WithAttributes(
+		attribute.String("schedule.id", req.GetScheduleId()),
+	))
+	defer span.End()
+
+	if err := s.Admins.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	found, err := service.DeletePriceSchedule(ctx, req.GetScheduleId(), s.RdbInstance)
+	if err != nil {
+		return &proto.DeletePriceScheduleResponse{
+			Message: "Failed to delete the price schedule. error: " + err.Error(),
+		}, err
+	}
+	if !found {
+		return &proto.DeletePriceScheduleResponse{
+			Message: "No price schedule found",
+		}, status.Error(codes.NotFound, "no price schedule found")
+	}
+	s.refreshSchedules(ctx)
+
+	return &proto.DeletePriceScheduleResponse{Message: "Successfully deleted the price schedule"}, nil
+}
+
+// refreshSchedules reloads the price schedules after a change, so this
+// replica applies it right away. Other replicas pick it up on their next
+// periodic reload.
+func (s *Server) refreshSchedules(ctx context.Context) {
+	database.IssueConsistencyToken(ctx)
+	if err := s.Schedules.Refresh(database.WithPrimary(ctx)); err != nil {
+		logging.FromContext(ctx).Error("failed to reload price schedules", "error", err)
+	}
+}
+
+func (s *Server) SetPriceTiers(ctx context.Context, req *proto.SetPriceTiersRequest) (*proto.SetPriceTiersResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.SetPriceTiers", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+		attribute.String("price_tier.customer_group", req.GetCustomerGroup()),
+	))
+	defer span.End()
+
+	if len(req.GetCustomerGroup()) > 50 {
+		return &proto.SetPriceTiersResponse{
+			Message: "Invalid customer group",
+		}, status.Error(codes.InvalidArgument, "customer_group: must not exceed 50 characters")
+	}
+
+	ctx = database.WithPrimary(ctx)
+	product, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId())
+	if err != nil {
+		return &proto.SetPriceTiersResponse{Message: "Failed to set the price tiers. error: " + err.Error()}, err
+	}
+
+	var tiers []models.PriceTier
+	for i, tier := range req.GetTiers() {
+		price, err := moneyFromProto(tier.GetUnitPrice())
+		if err != nil {
+			return &proto.SetPriceTiersResponse{
+				Message: "Invalid price tier. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "tiers[%d]: %v", i, err)
+		}
+		tiers = append(tiers, models.PriceTier{
+			ProductId:     product.ID,
+			CustomerGroup: req.GetCustomerGroup(),
+			MinQuantity:   tier.GetMinQuantity(),
+			UnitPrice:     price,
+		})
+	}
+	if err := pricing.ValidateTiers(tiers, product.Price.Currency); err != nil {
+		return &proto.SetPriceTiersResponse{
+			Message: "Invalid price tier. error: " + err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = service.SetPriceTiers(ctx, req.GetProductId(), req.GetCustomerGroup(), tiers, s.RdbInstance)
+	if err != nil {
+		return &proto.SetPriceTiersResponse{
+			Message: "Failed to set the price tiers. error: " + err.Error(),
+		}, err
+	}
+	s.afterWrite(ctx)
+
+	return &proto.SetPriceTiersResponse{Message: "Successfully set the price tiers"}, nil
+}
+
+func (s *Server) QuotePrice(ctx context.Context, req *proto.QuotePriceRequest) (*proto.QuotePriceResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.QuotePrice", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.Int("quote.quantity", int(req.GetQuantity())),
+		attribute.String("price_tier.customer_group", req.GetCustomerGroup()),
+	))
+	defer span.End()
+
+	if req.GetQuantity() < 1 {
+		return &proto.QuotePriceResponse{
+			Message: "Invalid quantity",
+		}, status.Error(codes.InvalidArgument, "quantity: must be at least 1")
+	}
+
+	product, found, err := s.productCache(ctx).Get(ctx, req.GetProductId(), s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !visible(product, "") {
+		return &proto.QuotePriceResponse{
+			Message: "No products found",
+		}, fmt.Errorf("no products found")
+	}
+
+	products, err := service.LoadPriceTiers(ctx, []models.Product{product}, req.GetCustomerGroup(), s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	products, err = s.effectivePrices(ctx, products, req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+	if currency := req.GetCurrency(); currency != "" && products[0].Price.Currency != currency {
+		return &proto.QuotePriceResponse{
+			Message: "Failed to quote the price",
+		}, status.Errorf(codes.FailedPrecondition, "%v from %s to %s", pricing.ErrNoRate, products[0].Price.Currency, currency)
+	}
+
+	quote, err := pricing.QuoteProduct(products[0], req.GetQuantity())
+	if err != nil {
+		return &proto.QuotePriceResponse{
+			Message: "Failed to quote the price. error: " + err.Error(),
+		}, status.Errorf(codes.InvalidArgument, "quantity: %v", err)
+	}
+
+	response := &proto.QuotePriceResponse{
+		Message:       "Successfully quoted the price",
+		UnitPrice:     moneyToProto(quote.UnitPrice),
+		ExtendedPrice: moneyToProto(quote.Extended),
+		ListPrice:     moneyToProto(quote.ListPrice),
+	}
+	if quote.Tier != nil {
+		response.TierMinQuantity = quote.Tier.MinQuantity
+	}
+	return response, nil
+}
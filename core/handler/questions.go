@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultQuestionPageSize = 20
+	maxQuestionPageSize     = 100
+)
+
+// Limits of the question and answer bodies, matching the sizes of their
+// columns.
+const (
+	maxQuestionBodyLength = 1000
+	maxAnswerBodyLength   = 2000
+)
+
+func (s *Server) AskQuestion(ctx context.Context, req *proto.AskQuestionRequest) (*proto.AskQuestionResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.AskQuestion", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("question.customer_id", req.GetCustomerId()),
+	))
+	defer span.End()
+
+	if err := checkEntry(req.GetAuthorName(), req.GetBody(), maxQuestionBodyLength); err != nil {
+		return &proto.AskQuestionResponse{Message: "Invalid question. error: " + err.Error()}, err
+	}
+	customerId, err := uuid.Parse(req.GetCustomerId())
+	if err != nil {
+		err = status.Error(codes.InvalidArgument, "customer_id: must be a UUID")
+		return &proto.AskQuestionResponse{Message: "Invalid question. error: " + err.Error()}, err
+	}
+
+	product, found, err := s.productCache(ctx).Get(ctx, req.GetProductId(), s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !visible(product, "") {
+		err := status.Error(codes.NotFound, "product not found")
+		return &proto.AskQuestionResponse{Message: "Failed to ask the question. error: " + err.Error()}, err
+	}
+	if product.SellerId == customerId {
+		err := status.Error(codes.FailedPrecondition, "sellers cannot ask questions about their own products")
+		return &proto.AskQuestionResponse{Message: "Failed to ask the question. error: " + err.Error()}, err
+	}
+
+	question := models.Question{
+		ProductId:  product.ID,
+		CustomerId: customerId,
+		AuthorName: strings.TrimSpace(req.GetAuthorName()),
+		Body:       strings.TrimSpace(req.GetBody()),
+		Status:     models.QuestionStatusPublished,
+	}
+	if s.QuestionApproval {
+		question.Status = models.QuestionStatusPending
+	}
+	question, err = service.AskQuestion(ctx, question, s.RdbInstance)
+	if err != nil {
+		return &proto.AskQuestionResponse{Message: "Failed to ask the question. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+	s.Events.Notify()
+
+	return &proto.AskQuestionResponse{Message: "Successfully asked the question", Question: questionToProto(question)}, nil
+}
+
+func (s *Server) AnswerQuestion(ctx context.Context, req *proto.AnswerQuestionRequest) (*proto.AnswerQuestionResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.AnswerQuestion", trace.WithAttributes(
+		attribute.String("question.id", req.GetQuestionId()),
+		attribute.String("answer.author_id", req.GetAuthorId()),
+	))
+	defer span.End()
+
+	if err := checkEntry(req.GetAuthorName(), req.GetBody(), maxAnswerBodyLength); err != nil {
+		return &proto.AnswerQuestionResponse{Message: "Invalid answer. error: " + err.Error()}, err
+	}
+	authorId, err := uuid.Parse(req.GetAuthorId())
+	if err != nil {
+		err = status.Error(codes.InvalidArgument, "author_id: must be a UUID")
+		return &proto.AnswerQuestionResponse{Message: "Invalid answer. error: " + err.Error()}, err
+	}
+
+	question, err := service.GetQuestion(ctx, req.GetQuestionId(), s.RdbInstance)
+	if err == nil && question.Status != models.QuestionStatusPublished {
+		err = service.ErrQuestionNotFound
+	}
+	if errors.Is(err, service.ErrQuestionNotFound) {
+		err = status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &proto.AnswerQuestionResponse{Message: "Failed to answer the question. error: " + err.Error()}, err
+	}
+	product, found, err := s.productCache(ctx).Get(ctx, question.ProductId.String(), s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !visible(product, "") {
+		err := status.Error(codes.NotFound, "product not found")
+		return &proto.AnswerQuestionResponse{Message: "Failed to answer the question. error: " + err.Error()}, err
+	}
+
+	answer := models.Answer{
+		QuestionId: question.ID,
+		AuthorId:   authorId,
+		AuthorName: strings.TrimSpace(req.GetAuthorName()),
+		AuthorRole: models.AnswerByCustomer,
+		Body:       strings.TrimSpace(req.GetBody()),
+		Status:     models.QuestionStatusPublished,
+	}
+	if product.SellerId == authorId {
+		// Only the seller agents authenticate the seller, anyone else could
+		// answer in the name of the seller
+		if err := s.SellerAgents.Check(ctx); err != nil {
+			return &proto.AnswerQuestionResponse{Message: "Unauthorized to answer as the seller. error: " + err.Error()}, err
+		}
+		answer.AuthorRole = models.AnswerBySeller
+	}
+	if s.QuestionApproval {
+		answer.Status = models.QuestionStatusPending
+	}
+	answer, err = service.AnswerQuestion(ctx, answer, s.RdbInstance)
+	if errors.Is(err, service.ErrQuestionNotFound) {
+		err = status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &proto.AnswerQuestionResponse{Message: "Failed to answer the question. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.AnswerQuestionResponse{Message: "Successfully answered the question", Answer: answerToProto(answer)}, nil
+}
+
+func (s *Server) Upvote(ctx context.Context, req *proto.UpvoteRequest) (*proto.UpvoteResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.Upvote", trace.WithAttributes(
+		attribute.String("question.id", req.GetQuestionId()),
+		attribute.String("answer.id", req.GetAnswerId()),
+		attribute.String("upvote.customer_id", req.GetCustomerId()),
+	))
+	defer span.End()
+
+	customerId, err := uuid.Parse(req.GetCustomerId())
+	if err != nil {
+		err = status.Error(codes.InvalidArgument, "customer_id: must be a UUID")
+		return &proto.UpvoteResponse{Message: "Invalid upvote. error: " + err.Error()}, err
+	}
+
+	var upvotes int32
+	switch entry := req.GetEntry().(type) {
+	case *proto.UpvoteRequest_QuestionId:
+		upvotes, err = service.UpvoteQuestion(ctx, entry.QuestionId, customerId, s.RdbInstance)
+	case *proto.UpvoteRequest_AnswerId:
+		upvotes, err = service.UpvoteAnswer(ctx, entry.AnswerId, customerId, s.RdbInstance)
+	default:
+		err = status.Error(codes.InvalidArgument, "question_id or answer_id: is required")
+		return &proto.UpvoteResponse{Message: "Invalid upvote. error: " + err.Error()}, err
+	}
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound), errors.Is(err, service.ErrAnswerNotFound):
+		err = status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrAlreadyUpvoted):
+		err = status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrOwnEntry):
+		err = status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return &proto.UpvoteResponse{Message: "Failed to upvote. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.UpvoteResponse{Message: "Successfully upvoted", Upvotes: upvotes}, nil
+}
+
+func (s *Server) ListQuestions(ctx context.Context, req *proto.ListQuestionsRequest) (*proto.ListQuestionsResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ListQuestions", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	questionStatus, answerStatus := req.GetStatus(), req.GetAnswerStatus()
+	if questionStatus == "" {
+		questionStatus = models.QuestionStatusPublished
+	}
+	if answerStatus == "" {
+		answerStatus = models.QuestionStatusPublished
+	}
+	if questionStatus != models.QuestionStatusPublished || answerStatus != models.QuestionStatusPublished {
+		if err := s.Moderators.Check(ctx); err != nil {
+			return nil, err
+		}
+	}
+	cursor, err := decodeQuestionCursor(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultQuestionPageSize
+	}
+	pageSize = min(pageSize, maxQuestionPageSize)
+
+	questions, err := service.GetQuestions(ctx, req.GetProductId(), questionStatus, answerStatus, pageSize, cursor, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.ListQuestionsResponse{Message: "Successfully retrieved the questions"}
+	for _, question := range questions {
+		response.Questions = append(response.Questions, questionToProto(question))
+	}
+	if len(questions) == pageSize {
+		last := questions[len(questions)-1]
+		response.NextPageToken = encodeQuestionCursor(service.QuestionCursor{
+			Upvotes:    last.Upvotes,
+			CreatedAt:  last.CreatedAt,
+			QuestionId: last.ID.String(),
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) ModerateQuestion(ctx context.Context, req *proto.ModerateQuestionRequest) (*proto.ModerateQuestionResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ModerateQuestion", trace.WithAttributes(
+		attribute.String("question.id", req.GetQuestionId()),
+		attribute.String("question.status", req.GetStatus()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkModeration(req.GetStatus(), req.GetReason()); err != nil {
+		return &proto.ModerateQuestionResponse{Message: "Invalid moderation. error: " + err.Error()}, err
+	}
+
+	question, err := service.ModerateQuestion(ctx, req.GetQuestionId(), req.GetStatus(), req.GetReason(), s.RdbInstance)
+	if errors.Is(err, service.ErrQuestionNotFound) {
+		err = status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &proto.ModerateQuestionResponse{Message: "Failed to moderate the question. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+	s.Events.Notify()
+
+	return &proto.ModerateQuestionResponse{Message: "Successfully moderated the question", Question: questionToProto(question)}, nil
+}
+
+func (s *Server) ModerateAnswer(ctx context.Context, req *proto.ModerateAnswerRequest) (*proto.ModerateAnswerResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ModerateAnswer", trace.WithAttributes(
+		attribute.String("answer.id", req.GetAnswerId()),
+		attribute.String("answer.status", req.GetStatus()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkModeration(req.GetStatus(), req.GetReason()); err != nil {
+		return &proto.ModerateAnswerResponse{Message: "Invalid moderation. error: " + err.Error()}, err
+	}
+
+	answer, err := service.ModerateAnswer(ctx, req.GetAnswerId(), req.GetStatus(), req.GetReason(), s.RdbInstance)
+	if errors.Is(err, service.ErrAnswerNotFound) {
+		err = status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &proto.ModerateAnswerResponse{Message: "Failed to moderate the answer. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.ModerateAnswerResponse{Message: "Successfully moderated the answer", Answer: answerToProto(answer)}, nil
+}
+
+// checkEntry validates the author name and the body of a question or an
+// answer.
+func checkEntry(authorName string, body string, maxBodyLength int) error {
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+	}{
+		{"author_name", authorName, maxAuthorNameLength},
+		{"body", body, maxBodyLength},
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(field.value) == "" {
+			return status.Errorf(codes.InvalidArgument, "%s: is required", field.name)
+		}
+		if utf8.RuneCountInString(field.value) > field.maxLength {
+			return status.Errorf(codes.InvalidArgument, "%s: must not exceed %d characters", field.name, field.maxLength)
+		}
+	}
+	return nil
+}
+
+// checkModeration validates the decision of a moderator on a question or an
+// answer.
+func checkModeration(to string, reason string) error {
+	if to != models.QuestionStatusPublished && to != models.QuestionStatusRejected {
+		return status.Errorf(codes.InvalidArgument, "status: must be %s or %s", models.QuestionStatusPublished, models.QuestionStatusRejected)
+	}
+	return checkReason(reason, to == models.QuestionStatusRejected)
+}
+
+// encodeQuestionCursor returns the opaque page token of a cursor.
+func encodeQuestionCursor(cursor service.QuestionCursor) string {
+	token := strconv.Itoa(int(cursor.Upvotes)) + "/" + cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "/" + cursor.QuestionId
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// decodeQuestionCursor parses a page token, returning nil for the first page.
+func decodeQuestionCursor(token string) (*service.QuestionCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	invalid := status.Error(codes.InvalidArgument, "page_token: is invalid")
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), "/")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+	upvotes, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return nil, invalid
+	}
+	cursor := service.QuestionCursor{Upvotes: int32(upvotes), QuestionId: parts[2]}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+		return nil, invalid
+	}
+	if _, err := uuid.Parse(cursor.QuestionId); err != nil {
+		return nil, invalid
+	}
+	return &cursor, nil
+}
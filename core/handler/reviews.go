@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultReviewPageSize = 20
+	maxReviewPageSize     = 100
+)
+
+// Limits of the review fields, matching the sizes of their columns except
+// for the body.
+const (
+	maxAuthorNameLength  = 100
+	maxReviewTitleLength = 200
+	maxReviewBodyLength  = 5000
+)
+
+func (s *Server) CreateReview(ctx context.Context, req *proto.CreateReviewRequest) (*proto.CreateReviewResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.CreateReview", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("review.customer_id", req.GetCustomerId()),
+	))
+	defer span.End()
+
+	if err := checkReview(req); err != nil {
+		return &proto.CreateReviewResponse{Message: "Invalid review. error: " + err.Error()}, err
+	}
+	if req.GetVerifiedPurchase() {
+		if err := s.PurchaseVerifiers.Check(ctx); err != nil {
+			return &proto.CreateReviewResponse{Message: "Failed to verify the purchase. error: " + err.Error()}, err
+		}
+	}
+	customerId, err := uuid.Parse(req.GetCustomerId())
+	if err != nil {
+		err = status.Error(codes.InvalidArgument, "customer_id: must be a UUID")
+		return &proto.CreateReviewResponse{Message: "Invalid review. error: " + err.Error()}, err
+	}
+
+	product, found, err := s.productCache(ctx).Get(ctx, req.GetProductId(), s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !visible(product, "") {
+		err := status.Error(codes.NotFound, "product not found")
+		return &proto.CreateReviewResponse{Message: "Failed to create the review. error: " + err.Error()}, err
+	}
+	if product.SellerId == customerId {
+		err := status.Error(codes.FailedPrecondition, "sellers cannot review their own products")
+		return &proto.CreateReviewResponse{Message: "Failed to create the review. error: " + err.Error()}, err
+	}
+
+	review := models.Review{
+		ProductId:        product.ID,
+		CustomerId:       customerId,
+		AuthorName:       strings.TrimSpace(req.GetAuthorName()),
+		Rating:           req.GetRating(),
+		Title:            strings.TrimSpace(req.GetTitle()),
+		Body:             strings.TrimSpace(req.GetBody()),
+		VerifiedPurchase: req.GetVerifiedPurchase(),
+		Status:           models.ReviewStatusPublished,
+	}
+	if s.ReviewApproval {
+		review.Status = models.ReviewStatusPending
+	}
+	review, err = service.CreateReview(ctx, review, s.RdbInstance)
+	if errors.Is(err, service.ErrDuplicateReview) {
+		err = status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return &proto.CreateReviewResponse{Message: "Failed to create the review. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.CreateReviewResponse{Message: "Successfully created the review", Review: reviewToProto(review)}, nil
+}
+
+func (s *Server) ListReviews(ctx context.Context, req *proto.ListReviewsRequest) (*proto.ListReviewsResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ListReviews", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	filter := service.ReviewFilter{Status: req.GetStatus(), Rating: req.GetRating()}
+	if filter.Status == "" {
+		filter.Status = models.ReviewStatusPublished
+	}
+	if filter.Status != models.ReviewStatusPublished {
+		if err := s.Moderators.Check(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Rating < 0 || filter.Rating > 5 {
+		return nil, status.Error(codes.InvalidArgument, "rating: must be between 1 and 5")
+	}
+	cursor, err := decodeReviewCursor(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultReviewPageSize
+	}
+	pageSize = min(pageSize, maxReviewPageSize)
+
+	reviews, err := service.GetReviews(ctx, req.GetProductId(), filter, pageSize, cursor, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := service.GetProductRatings(ctx, []string{req.GetProductId()}, s.RdbInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.ListReviewsResponse{Message: "Successfully retrieved the reviews"}
+	if rating, ok := ratings[req.GetProductId()]; ok {
+		response.Rating = ratingToProto(rating)
+	}
+	for _, review := range reviews {
+		response.Reviews = append(response.Reviews, reviewToProto(review))
+	}
+	if len(reviews) == pageSize {
+		last := reviews[len(reviews)-1]
+		response.NextPageToken = encodeReviewCursor(service.ReviewCursor{CreatedAt: last.CreatedAt, ReviewId: last.ID.String()})
+	}
+	return response, nil
+}
+
+func (s *Server) ModerateReview(ctx context.Context, req *proto.ModerateReviewRequest) (*proto.ModerateReviewResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ModerateReview", trace.WithAttributes(
+		attribute.String("review.id", req.GetReviewId()),
+		attribute.String("review.status", req.GetStatus()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetStatus() != models.ReviewStatusPublished && req.GetStatus() != models.ReviewStatusRejected {
+		err := status.Errorf(codes.InvalidArgument, "status: must be %s or %s", models.ReviewStatusPublished, models.ReviewStatusRejected)
+		return &proto.ModerateReviewResponse{Message: "Invalid status. error: " + err.Error()}, err
+	}
+	if err := checkReason(req.GetReason(), req.GetStatus() == models.ReviewStatusRejected); err != nil {
+		return &proto.ModerateReviewResponse{Message: "Invalid reason. error: " + err.Error()}, err
+	}
+
+	review, err := service.ModerateReview(ctx, req.GetReviewId(), req.GetStatus(), req.GetReason(), s.RdbInstance)
+	if errors.Is(err, service.ErrReviewNotFound) {
+		err = status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &proto.ModerateReviewResponse{Message: "Failed to moderate the review. error: " + err.Error()}, err
+	}
+	database.IssueConsistencyToken(ctx)
+
+	return &proto.ModerateReviewResponse{Message: "Successfully moderated the review", Review: reviewToProto(review)}, nil
+}
+
+// loadRatings sets the rating of each product on its API representation.
+func (s *Server) loadRatings(ctx context.Context, products []*proto.Product) error {
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.GetProductId()
+	}
+	ratings, err := service.GetProductRatings(ctx, ids, s.RdbInstance)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		if rating, ok := ratings[product.GetProductId()]; ok {
+			product.Rating = ratingToProto(rating)
+		}
+	}
+	return nil
+}
+
+func checkReview(req *proto.CreateReviewRequest) error {
+	if req.GetRating() < 1 || req.GetRating() > 5 {
+		return status.Error(codes.InvalidArgument, "rating: must be between 1 and 5")
+	}
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+	}{
+		{"author_name", req.GetAuthorName(), maxAuthorNameLength},
+		{"title", req.GetTitle(), maxReviewTitleLength},
+		{"body", req.GetBody(), maxReviewBodyLength},
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(field.value) == "" {
+			return status.Errorf(codes.InvalidArgument, "%s: is required", field.name)
+		}
+		if utf8.RuneCountInString(field.value) > field.maxLength {
+			return status.Errorf(codes.InvalidArgument, "%s: must not exceed %d characters", field.name, field.maxLength)
+		}
+	}
+	return nil
+}
+
+// encodeReviewCursor returns the opaque page token of a cursor.
+func encodeReviewCursor(cursor service.ReviewCursor) string {
+	token := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "/" + cursor.ReviewId
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// decodeReviewCursor parses a page token, returning nil for the first page.
+func decodeReviewCursor(token string) (*service.ReviewCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	invalid := status.Error(codes.InvalidArgument, "page_token: is invalid")
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, reviewId, ok := strings.Cut(string(raw), "/")
+	if !ok {
+		return nil, invalid
+	}
+	cursor := service.ReviewCursor{ReviewId: reviewId}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, invalid
+	}
+	if _, err := uuid.Parse(reviewId); err != nil {
+		return nil, invalid
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/delivery"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/core/shipping"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) QuoteShipping(ctx context.Context, req *proto.QuoteShippingRequest) (*proto.QuoteShippingResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.QuoteShipping", trace.WithAttributes(
+		attribute.String("shipping.zone", req.GetZone()),
+		attribute.Int("shipping.items", len(req.GetItems())),
+	))
+	defer span.End()
+
+	zone, err := s.Shipping.Zone(req.GetZone())
+	if err != nil {
+		return &proto.QuoteShippingResponse{
+			Message: "Invalid shipping zone. error: " + err.Error(),
+		}, status.Errorf(codes.InvalidArgument, "zone: %v", err)
+	}
+	if len(req.GetItems()) == 0 {
+		return &proto.QuoteShippingResponse{
+			Message: "No items to ship",
+		}, status.Error(codes.InvalidArgument, "items: at least one item is required")
+	}
+
+	var productIds []string
+	for i, item := range req.GetItems() {
+		if item.GetQuantity() < 1 {
+			return &proto.QuoteShippingResponse{
+				Message: "Invalid quantity",
+			}, status.Errorf(codes.InvalidArgument, "items[%d].quantity: must be at least 1", i)
+		}
+		productIds = append(productIds, item.GetProductId())
+	}
+
+	found, err := s.productCache(ctx).GetMany(ctx, productIds, s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+	products := make([]models.Product, len(productIds))
+	for i, productId := range productIds {
+		product, ok := found[productId]
+		if !ok || !visible(product, "") {
+			return &proto.QuoteShippingResponse{
+				Message: "No products found",
+			}, status.Errorf(codes.NotFound, "items[%d]: no product %s", i, productId)
+		}
+		products[i] = product
+	}
+
+	// Base prices are charged in the currency of the zone rates
+	products, err = s.effectivePrices(ctx, products, zone.Currency)
+	if err != nil {
+		return nil, err
+	}
+	// Products without an exchange rate kept their currency, which only
+	// matters for a base price to charge
+	for i, product := range products {
+		base := product.ShippingBasePrice
+		if base.Currency != zone.Currency && base.Amount == 0 {
+			products[i].ShippingBasePrice = money.Money{Currency: zone.Currency}
+		} else if base.Currency != zone.Currency {
+			return &proto.QuoteShippingResponse{
+				Message: "Failed to quote the shipment",
+			}, status.Errorf(codes.FailedPrecondition, "items[%d]: %v from %s to %s", i, pricing.ErrNoRate, base.Currency, zone.Currency)
+		}
+	}
+	items := make([]shipping.Item, len(products))
+	for i, product := range products {
+		items[i] = shipping.Item{Product: product, Quantity: req.GetItems()[i].GetQuantity()}
+	}
+
+	quote, err := s.Shipping.Quote(zone, items)
+	if errors.Is(err, shipping.ErrOverweight) || errors.Is(err, money.ErrOutOfRange) {
+		return &proto.QuoteShippingResponse{
+			Message: "Failed to quote the shipment. error: " + err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.QuoteShippingResponse{
+		Message:        fmt.Sprintf("Successfully quoted the shipment of %d items", len(items)),
+		BillableWeight: quote.BillableWeight,
+		BasePrice:      moneyToProto(quote.BasePrice),
+		Rate:           moneyToProto(quote.Rate),
+		Total:          moneyToProto(quote.Total),
+	}
+	for _, line := range quote.Items {
+		response.Items = append(response.Items, &proto.ItemShippingQuote{
+			ProductId:        line.Product.ID.String(),
+			Quantity:         line.Quantity,
+			ActualWeight:     line.ActualWeight,
+			VolumetricWeight: line.VolumetricWeight,
+			BillableWeight:   line.BillableWeight,
+			BasePrice:        moneyToProto(line.BasePrice),
+			Rate:             moneyToProto(line.Rate),
+			Total:            moneyToProto(line.Total),
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) EstimateDelivery(ctx context.Context, req *proto.EstimateDeliveryRequest) (*proto.EstimateDeliveryResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.EstimateDelivery", trace.WithAttributes(
+		attribute.String("delivery.zone", req.GetZone()),
+		attribute.Int("delivery.products", len(req.GetProductIds())),
+	))
+	defer span.End()
+
+	transit, err := s.Delivery.Zone(req.GetZone())
+	if err != nil {
+		return &proto.EstimateDeliveryResponse{
+			Message: "Invalid delivery zone. error: " + err.Error(),
+		}, status.Errorf(codes.InvalidArgument, "zone: %v", err)
+	}
+	if len(req.GetProductIds()) == 0 {
+		return &proto.EstimateDeliveryResponse{
+			Message: "No products to deliver",
+		}, status.Error(codes.InvalidArgument, "product_ids: at least one product is required")
+	}
+	orderedAt := time.Now()
+	if req.GetOrderedAt() != nil {
+		if err := req.GetOrderedAt().CheckValid(); err != nil {
+			return &proto.EstimateDeliveryResponse{
+				Message: "Invalid order time. error: " + err.Error(),
+			}, status.Errorf(codes.InvalidArgument, "ordered_at: %v", err)
+		}
+		orderedAt = req.GetOrderedAt().AsTime()
+	}
+
+	found, err := s.productCache(ctx).GetMany(ctx, req.GetProductIds(), s.loadProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.EstimateDeliveryResponse{Message: "Successfully estimated the delivery"}
+	var earliest, latest time.Time
+	for i, productId := range req.GetProductIds() {
+		product, ok := found[productId]
+		if !ok || !visible(product, "") {
+			return &proto.EstimateDeliveryResponse{
+				Message: "No products found",
+			}, status.Errorf(codes.NotFound, "product_ids[%d]: no product %s", i, productId)
+		}
+
+		estimate := s.Delivery.Estimate(product.SellerId.String(), int(product.BaseDeliveryTimelines), transit, orderedAt)
+		response.Items = append(response.Items, &proto.DeliveryEstimate{
+			ProductId:    productId,
+			SellerId:     product.SellerId.String(),
+			ShipDate:     estimate.ShipDate.Format(delivery.DateLayout),
+			EarliestDate: estimate.Earliest.Format(delivery.DateLayout),
+			LatestDate:   estimate.Latest.Format(delivery.DateLayout),
+		})
+		if estimate.Earliest.After(earliest) {
+			earliest = estimate.Earliest
+		}
+		if estimate.Latest.After(latest) {
+			latest = estimate.Latest
+		}
+	}
+	response.EarliestDate = earliest.Format(delivery.DateLayout)
+	response.LatestDate = latest.Format(delivery.DateLayout)
+	return response, nil
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"github.com/tittuvarghese/ss-go-product-service/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) SubmitForReview(ctx context.Context, req *proto.SubmitForReviewRequest) (*proto.SubmitForReviewResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.SubmitForReview", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+	))
+	defer span.End()
+
+	product, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId())
+	if err != nil {
+		return &proto.SubmitForReviewResponse{Message: "Failed to submit the product for review. error: " + err.Error()}, err
+	}
+	// Active products only go back to review when an update trips the
+	// moderation rules
+	if product.Status != models.ProductStatusDraft && product.Status != models.ProductStatusRejected {
+		err := status.Errorf(codes.FailedPrecondition, "%v from %s to %s", service.ErrInvalidTransition, product.Status, models.ProductStatusPendingReview)
+		return &proto.SubmitForReviewResponse{Message: "Failed to submit the product for review. error: " + err.Error()}, err
+	}
+	if s.ModerationRules == nil {
+		if err := s.transition(ctx, req, req.GetProductId(), models.ProductStatusPendingReview, ""); err != nil {
+			return &proto.SubmitForReviewResponse{Message: "Failed to submit the product for review. error: " + err.Error()}, err
+		}
+		return &proto.SubmitForReviewResponse{
+			Message: "Successfully submitted the product for review",
+			Status:  models.ProductStatusPendingReview,
+		}, nil
+	}
+
+	// The rules decide right away, leaving only the products they send to
+	// review to the moderators
+	product.Status, product.StatusReason = models.ProductStatusPendingReview, ""
+	outcome, err := s.moderate(&product)
+	if err == nil {
+		err = service.UpdateProduct(ctx, product, s.auditFor(ctx, req), s.RdbInstance)
+	}
+	if err != nil {
+		return &proto.SubmitForReviewResponse{Message: "Failed to submit the product for review. error: " + err.Error()}, err
+	}
+	s.afterWrite(ctx, req.GetProductId())
+
+	return &proto.SubmitForReviewResponse{
+		Message:    "Successfully submitted the product for review",
+		Status:     product.Status,
+		Moderation: outcome,
+	}, nil
+}
+
+func (s *Server) ApproveProduct(ctx context.Context, req *proto.ApproveProductRequest) (*proto.ApproveProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.ApproveProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkReason(req.GetReason(), false); err != nil {
+		return &proto.ApproveProductResponse{Message: "Invalid reason. error: " + err.Error()}, err
+	}
+	if err := s.transition(ctx, req, req.GetProductId(), models.ProductStatusActive, req.GetReason()); err != nil {
+		return &proto.ApproveProductResponse{Message: "Failed to approve the product. error: " + err.Error()}, err
+	}
+	return &proto.ApproveProductResponse{Message: "Successfully approved the product"}, nil
+}
+
+func (s *Server) RejectProduct(ctx context.Context, req *proto.RejectProductRequest) (*proto.RejectProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.RejectProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkReason(req.GetReason(), true); err != nil {
+		return &proto.RejectProductResponse{Message: "Invalid reason. error: " + err.Error()}, err
+	}
+	if err := s.transition(ctx, req, req.GetProductId(), models.ProductStatusRejected, req.GetReason()); err != nil {
+		return &proto.RejectProductResponse{Message: "Failed to reject the product. error: " + err.Error()}, err
+	}
+	return &proto.RejectProductResponse{Message: "Successfully rejected the product"}, nil
+}
+
+func (s *Server) SuspendProduct(ctx context.Context, req *proto.SuspendProductRequest) (*proto.SuspendProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.SuspendProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+	))
+	defer span.End()
+
+	if err := s.Moderators.Check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkReason(req.GetReason(), true); err != nil {
+		return &proto.SuspendProductResponse{Message: "Invalid reason. error: " + err.Error()}, err
+	}
+	if err := s.transition(ctx, req, req.GetProductId(), models.ProductStatusSuspended, req.GetReason()); err != nil {
+		return &proto.SuspendProductResponse{Message: "Failed to suspend the product. error: " + err.Error()}, err
+	}
+	return &proto.SuspendProductResponse{Message: "Successfully suspended the product"}, nil
+}
+
+func (s *Server) DiscontinueProduct(ctx context.Context, req *proto.DiscontinueProductRequest) (*proto.DiscontinueProductResponse, error) {
+	ctx, span := tracer.Start(ctx, "Server.DiscontinueProduct", trace.WithAttributes(
+		attribute.String("product.id", req.GetProductId()),
+		attribute.String("product.seller_id", req.GetSellerId()),
+	))
+	defer span.End()
+
+	if _, err := s.sellerProduct(ctx, req.GetProductId(), req.GetSellerId()); err != nil {
+		return &proto.DiscontinueProductResponse{Message: "Failed to discontinue the product. error: " + err.Error()}, err
+	}
+	if err := s.transition(ctx, req, req.GetProductId(), models.ProductStatusDiscontinued, ""); err != nil {
+		return &proto.DiscontinueProductResponse{Message: "Failed to discontinue the product. error: " + err.Error()}, err
+	}
+	return &proto.DiscontinueProductResponse{Message: "Successfully discontinued the product"}, nil
+}
+
+// sellerProduct reads the product from the primary, failing unless it
+// belongs to the seller. Only the seller agents authenticate the seller, so
+// other callers are refused whatever seller_id they send.
+func (s *Server) sellerProduct(ctx context.Context, productId string, sellerId string) (models.Product, error) {
+	if err := s.SellerAgents.Check(ctx); err != nil {
+		return models.Product{}, err
+	}
+	product, err := s.primaryProduct(ctx, productId)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if product.SellerId.String() != sellerId {
+		return models.Product{}, fmt.Errorf("unauthorized to perform this operation")
+	}
+	return product, nil
+}
+
+// checkReason validates the reason of a moderation decision.
+func checkReason(reason string, required bool) error {
+	if required && strings.TrimSpace(reason) == "" {
+		return status.Error(codes.InvalidArgument, "reason: is required")
+	}
+	if utf8.RuneCountInString(reason) > models.MaxStatusReasonLength {
+		return status.Errorf(codes.InvalidArgument, "reason: must not exceed %d characters", models.MaxStatusReasonLength)
+	}
+	return nil
+}
+
+// transition moves a product to the given status and records who made the
+// change through which RPC.
+func (s *Server) transition(ctx context.Context, req interface{}, productId string, to string, reason string) error {
+	_, err := service.TransitionProduct(ctx, productId, to, reason, s.auditFor(ctx, req), s.RdbInstance)
+	if errors.Is(err, service.ErrProductNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, service.ErrInvalidTransition) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	s.afterWrite(ctx, productId)
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package images validates the images sellers upload for their products.
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	// Decoders of the supported formats
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image too large")
+	ErrEmpty           = errors.New("image is empty")
+)
+
+// extensions maps the supported content types to the extension of their
+// stored files.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Limits bound the images of a product.
+type Limits struct {
+	// MaxSize is the largest image accepted, in bytes.
+	MaxSize int64
+	// MaxPerProduct is the number of images a product may have.
+	MaxPerProduct int
+}
+
+// MaxPixels bounds the dimensions of images, which are decoded in memory to
+// render them.
+const MaxPixels = 50_000_000
+
+// checkPixels fails with ErrTooLarge when the image has more than MaxPixels.
+func checkPixels(config image.Config) error {
+	if config.Width*config.Height > MaxPixels {
+		return fmt.Errorf("%w, %dx%d pixels exceed the limit of %d", ErrTooLarge, config.Width, config.Height, MaxPixels)
+	}
+	return nil
+}
+
+// Info describes the content of an image.
+type Info struct {
+	ContentType string
+	Size        int64
+	// Width and Height are the dimensions of the image as displayed, once
+	// oriented.
+	Width  int
+	Height int
+	// Orientation is the EXIF orientation of the image, see StripMetadata.
+	Orientation int
+	// Checksum is the hex encoded SHA-256 of the content.
+	Checksum string
+}
+
+// Prepare sniffs the type of the content, whatever the client claims, reads
+// the dimensions of the image and strips its metadata. It returns the content
+// to store, which Info describes. Content which does not decode as the
+// sniffed type is rejected.
+func Prepare(content []byte) (Info, []byte, error) {
+	if len(content) == 0 {
+		return Info{}, nil, ErrEmpty
+	}
+	contentType := http.DetectContentType(content)
+	if _, ok := extensions[contentType]; !ok {
+		return Info{}, nil, fmt.Errorf("%w %s, expected JPEG, PNG, GIF or WebP", ErrUnsupportedType, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return Info{}, nil, fmt.Errorf("%w: decoding %s: %v", ErrUnsupportedType, contentType, err)
+	}
+	if err := checkPixels(config); err != nil {
+		return Info{}, nil, err
+	}
+	content, orientation, err := StripMetadata(content, contentType)
+	if err != nil {
+		return Info{}, nil, fmt.Errorf("%w: stripping metadata of %s: %v", ErrUnsupportedType, contentType, err)
+	}
+
+	width, height := config.Width, config.Height
+	if orientation > 4 {
+		width, height = height, width
+	}
+	sum := sha256.Sum256(content)
+	return Info{
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Width:       width,
+		Height:      height,
+		Orientation: orientation,
+		Checksum:    hex.EncodeToString(sum[:]),
+	}, content, nil
+}
+
+// Key returns the blob key of an image of a product.
+func Key(productId string, imageId string, contentType string) string {
+	return "products/" + productId + "/" + imageId + extensions[contentType]
+}
@@ -0,0 +1,129 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encode(t *testing.T, format string, width int, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	var out bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&out, img)
+	case "jpeg":
+		err = jpeg.Encode(&out, img, nil)
+	case "gif":
+		paletted := image.NewPaletted(img.Bounds(), color.Palette{color.White})
+		err = gif.Encode(&out, paletted, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// pngHeader returns the signature and header chunk of a PNG image of the
+// given dimensions, enough to decode its config but not the image.
+func pngHeader(width uint32, height uint32) []byte {
+	ihdr := make([]byte, 0, 17)
+	ihdr = append(ihdr, "IHDR"...)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	// 8 bit RGB, default compression, filter and interlacing
+	ihdr = append(ihdr, 8, 2, 0, 0, 0)
+
+	content := []byte("\x89PNG\r\n\x1a\n")
+	content = binary.BigEndian.AppendUint32(content, uint32(len(ihdr)-4))
+	content = append(content, ihdr...)
+	return binary.BigEndian.AppendUint32(content, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestPrepareSniffsTheContentType(t *testing.T) {
+	for format, contentType := range map[string]string{
+		"png":  "image/png",
+		"jpeg": "image/jpeg",
+		"gif":  "image/gif",
+	} {
+		info, content, err := Prepare(encode(t, format, 30, 20))
+		if err != nil {
+			t.Errorf("Prepare(%s): %v", format, err)
+			continue
+		}
+		if info.ContentType != contentType || info.Width != 30 || info.Height != 20 {
+			t.Errorf("Prepare(%s) = %s %dx%d, want %s 30x20", format, info.ContentType, info.Width, info.Height, contentType)
+		}
+		if info.Size != int64(len(content)) || len(info.Checksum) != 64 {
+			t.Errorf("Prepare(%s) = size %d, checksum %q for %d bytes", format, info.Size, info.Checksum, len(content))
+		}
+		if key := Key("p1", "i1", info.ContentType); key != "products/p1/i1"+extensions[contentType] {
+			t.Errorf("Key = %q", key)
+		}
+	}
+}
+
+func TestPrepareRejectsUnsupportedContent(t *testing.T) {
+	if _, _, err := Prepare(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Prepare(empty) = %v, want ErrEmpty", err)
+	}
+
+	// A PNG truncated after its signature sniffs as PNG but does not decode
+	truncated := encode(t, "png", 4, 4)[:12]
+	for name, content := range map[string][]byte{
+		"text":          []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"),
+		"pdf":           []byte("%PDF-1.7\n"),
+		"truncated png": truncated,
+	} {
+		if _, _, err := Prepare(content); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Prepare(%s) = %v, want ErrUnsupportedType", name, err)
+		}
+	}
+}
+
+func TestPixelLimit(t *testing.T) {
+	// 10000x5001 pixels, just over MaxPixels
+	huge := pngHeader(10000, MaxPixels/10000+1)
+	if _, _, err := Prepare(huge); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Prepare(huge) = %v, want ErrTooLarge", err)
+	}
+	if _, err := Render(huge, OrientationNormal, []Spec{{Name: RenditionThumbnail, MaxSize: 100}}, 80); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Render(huge) = %v, want ErrTooLarge", err)
+	}
+
+	if err := checkPixels(image.Config{Width: 10000, Height: MaxPixels / 10000}); err != nil {
+		t.Errorf("checkPixels at the limit: %v", err)
+	}
+}
+
+func TestRenderScalesDown(t *testing.T) {
+	renditions, err := Render(encode(t, "jpeg", 400, 200), OrientationNormal, []Spec{
+		{Name: RenditionThumbnail, MaxSize: 100},
+		{Name: RenditionLarge, MaxSize: 1000},
+	}, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renditions) != 2 {
+		t.Fatalf("Render returned %d renditions, want 2", len(renditions))
+	}
+	if r := renditions[0]; r.Width != 100 || r.Height != 50 || r.ContentType != "image/jpeg" {
+		t.Errorf("thumbnail = %s %dx%d, want image/jpeg 100x50", r.ContentType, r.Width, r.Height)
+	}
+	// Images are never scaled up
+	if r := renditions[1]; r.Width != 400 || r.Height != 200 {
+		t.Errorf("large = %dx%d, want 400x200", r.Width, r.Height)
+	}
+}
@@ -0,0 +1,226 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed image")
+
+// OrientationNormal is the EXIF orientation of upright images. Orientations
+// 2 to 8 flip or rotate the image, 5 to 8 swapping its width and height.
+const OrientationNormal = 1
+
+// StripMetadata removes the EXIF, XMP, IPTC and text metadata of JPEG, PNG and
+// WebP images without re-encoding them, keeping the segments needed to
+// render them such as ICC color profiles. The EXIF orientation of the image
+// is kept, alone, so it is still displayed upright, and returned. GIF images
+// carry no such metadata and are returned as is.
+func StripMetadata(content []byte, contentType string) ([]byte, int, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(content)
+	case "image/png":
+		stripped, err := stripPNG(content)
+		return stripped, OrientationNormal, err
+	case "image/webp":
+		return stripWebP(content)
+	}
+	return content, OrientationNormal, nil
+}
+
+// stripJPEG drops the APP segments other than JFIF (APP0), ICC profiles
+// (APP2) and Adobe color transforms (APP14), and the comments, up to the
+// start of the scan data.
+func stripJPEG(content []byte) ([]byte, int, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, 0, errMalformed
+	}
+	orientation := OrientationNormal
+	var kept [][]byte
+	for i := 2; i+4 <= len(content); {
+		if content[i] != 0xFF {
+			return nil, 0, errMalformed
+		}
+		marker := content[i+1]
+		if marker == 0xFF {
+			// Fill byte
+			i++
+			continue
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			kept = append(kept, content[i:i+2])
+			i += 2
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// The scan data up to the end of the image holds no metadata
+			kept = append(kept, content[i:])
+			return assembleJPEG(kept, orientation), orientation, nil
+		}
+		length := int(binary.BigEndian.Uint16(content[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(content) {
+			return nil, 0, errMalformed
+		}
+		segment := content[i+4 : end]
+		switch {
+		case marker == 0xE1:
+			if bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				orientation = exifOrientation(segment[6:])
+			}
+		case marker == 0xFE, marker >= 0xE1 && marker <= 0xEF && marker != 0xE2 && marker != 0xEE:
+		default:
+			kept = append(kept, content[i:end])
+		}
+		i = end
+	}
+	return nil, 0, errMalformed
+}
+
+// assembleJPEG writes the kept segments after the start of image, along with
+// an EXIF segment holding the orientation unless the image is upright. The
+// JFIF segment, when present, must stay first.
+func assembleJPEG(segments [][]byte, orientation int) []byte {
+	out := bytes.NewBuffer([]byte{0xFF, 0xD8})
+	if len(segments) > 0 && len(segments[0]) > 1 && segments[0][1] == 0xE0 {
+		out.Write(segments[0])
+		segments = segments[1:]
+	}
+	if orientation != OrientationNormal {
+		exif := append([]byte("Exif\x00\x00"), orientationTIFF(orientation)...)
+		out.Write([]byte{0xFF, 0xE1, 0, 0})
+		binary.BigEndian.PutUint16(out.Bytes()[out.Len()-2:], uint16(len(exif)+2))
+		out.Write(exif)
+	}
+	for _, segment := range segments {
+		out.Write(segment)
+	}
+	return out.Bytes()
+}
+
+// pngMetadata are the chunk types dropped from PNG images.
+var pngMetadata = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(content []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(content, []byte(signature)) {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.WriteString(signature)
+	for i := len(signature); i < len(content); {
+		if i+8 > len(content) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(content[i:]))
+		end := i + 12 + length
+		if end > len(content) {
+			return nil, errMalformed
+		}
+		if !pngMetadata[string(content[i+4:i+8])] {
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// stripWebP drops the XMP chunk of extended WebP images and replaces their
+// EXIF chunk by one holding the orientation only, updating the flags of the
+// VP8X header.
+func stripWebP(content []byte) ([]byte, int, error) {
+	if len(content) < 12 || string(content[:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+		return nil, 0, errMalformed
+	}
+	orientation := OrientationNormal
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:12])
+	flags := -1
+	for i := 12; i < len(content); {
+		if i+8 > len(content) {
+			return nil, 0, errMalformed
+		}
+		fourCC := string(content[i : i+4])
+		size := int(binary.LittleEndian.Uint32(content[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(content) {
+			return nil, 0, errMalformed
+		}
+		switch fourCC {
+		case "EXIF":
+			data := content[i+8 : i+8+size]
+			orientation = exifOrientation(bytes.TrimPrefix(data, []byte("Exif\x00\x00")))
+		case "XMP ":
+		case "VP8X":
+			if size > 0 {
+				flags = out.Len() + 8
+			}
+			out.Write(content[i:end])
+		default:
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+
+	// EXIF (0x08) and XMP (0x04) flags
+	if flags >= 0 {
+		out.Bytes()[flags] &^= 0x0C
+		if orientation != OrientationNormal {
+			out.Bytes()[flags] |= 0x08
+			tiff := orientationTIFF(orientation)
+			out.WriteString("EXIF")
+			binary.Write(out, binary.LittleEndian, uint32(len(tiff)))
+			out.Write(tiff)
+		}
+	}
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, orientation, nil
+}
+
+// exifOrientation reads the orientation tag of the first IFD of TIFF encoded
+// EXIF data, defaulting to upright.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return OrientationNormal
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		// Orientation, a SHORT
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= OrientationNormal && orientation <= 8 {
+				return orientation
+			}
+		}
+	}
+	return OrientationNormal
+}
+
+// orientationTIFF encodes EXIF data holding the orientation tag only.
+func orientationTIFF(orientation int) []byte {
+	return []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // Big endian header, first IFD at 8
+		0, 1, // One entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, // Orientation, one SHORT
+		0, 0, 0, 0, // No next IFD
+	}
+}
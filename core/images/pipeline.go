@@ -0,0 +1,214 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-core/logger"
+	"github.com/tittuvarghese/ss-go-product-service/core/blob"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+var log = logger.NewLogger("product-service")
+
+// ErrImageDeleted is returned by a RenditionSaver when the image was deleted
+// while its renditions were rendered.
+var ErrImageDeleted = errors.New("image deleted")
+
+// renderTimeout bounds the rendering of the renditions of one image.
+const renderTimeout = time.Minute
+
+// PendingLoader reads up to limit images waiting for their renditions.
+type PendingLoader func(ctx context.Context, limit int) ([]models.ProductImage, error)
+
+// RenditionSaver records the renditions of an image and their status.
+type RenditionSaver func(ctx context.Context, image models.ProductImage, renditions []models.ProductImageRendition, status string) error
+
+// PipelineOptions configures a Pipeline.
+type PipelineOptions struct {
+	Workers       int
+	QueueSize     int
+	SweepInterval time.Duration
+	Specs         []Spec
+	// Quality is the JPEG quality of the renditions, from 1 to 100
+	Quality int
+	Blobs   blob.BlobStore
+	Pending PendingLoader
+	Save    RenditionSaver
+}
+
+// Pipeline renders the renditions of stored images in a pool of workers.
+// Images are queued by Enqueue right after their upload; the ones it could
+// not queue, or left pending by a restart or a storage failure, are queued
+// again by a sweep every SweepInterval. A nil *Pipeline renders nothing.
+type Pipeline struct {
+	opts  PipelineOptions
+	queue chan models.ProductImage
+
+	mu       sync.Mutex
+	inFlight map[uuid.UUID]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPipeline starts the workers and the sweeps until Close is called.
+func NewPipeline(opts PipelineOptions) *Pipeline {
+	p := &Pipeline{
+		opts:     opts,
+		queue:    make(chan models.ProductImage, opts.QueueSize),
+		inFlight: map[uuid.UUID]struct{}{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	var workers sync.WaitGroup
+	workers.Add(opts.Workers + 1)
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			defer workers.Done()
+			p.work()
+		}()
+	}
+	go func() {
+		defer workers.Done()
+		p.sweep()
+	}()
+	go func() {
+		workers.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// Enqueue queues the image for rendering unless it already is. It never
+// blocks: when the queue is full the image is left to the next sweep.
+func (p *Pipeline) Enqueue(image models.ProductImage) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.inFlight[image.ID]; ok {
+		return false
+	}
+	select {
+	case p.queue <- image:
+		p.inFlight[image.ID] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops the workers once they finish the images they are rendering.
+// Queued images stay pending until the next start.
+func (p *Pipeline) Close(ctx context.Context) error {
+	close(p.stop)
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pipeline) work() {
+	for {
+		select {
+		case <-p.stop:
+			return
+		case image := <-p.queue:
+			p.render(image)
+			p.mu.Lock()
+			delete(p.inFlight, image.ID)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// sweep queues the pending images right away, then every interval.
+func (p *Pipeline) sweep() {
+	ticker := time.NewTicker(p.opts.SweepInterval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), p.opts.SweepInterval)
+		images, err := p.opts.Pending(ctx, p.opts.QueueSize)
+		cancel()
+		if err != nil {
+			log.Error("Error loading images pending renditions", err)
+		}
+		for _, image := range images {
+			p.Enqueue(image)
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// render stores the renditions of the image and records them. Images which
+// cannot be decoded are marked failed; storage failures leave the image
+// pending for the next sweep.
+func (p *Pipeline) render(image models.ProductImage) {
+	ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+	defer cancel()
+
+	content, err := p.opts.Blobs.Get(ctx, image.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		p.save(ctx, image, nil, models.RenditionsFailed)
+		return
+	}
+	if err != nil {
+		log.Error("Error reading image "+image.ID.String()+" for its renditions", err)
+		return
+	}
+	rendered, err := Render(content, int(image.Orientation), p.opts.Specs, p.opts.Quality)
+	if err != nil {
+		log.Error("Error rendering image "+image.ID.String(), err)
+		p.save(ctx, image, nil, models.RenditionsFailed)
+		return
+	}
+
+	renditions := make([]models.ProductImageRendition, 0, len(rendered))
+	for _, r := range rendered {
+		rendition := models.ProductImageRendition{
+			ImageId:     image.ID,
+			Name:        r.Name,
+			ContentType: r.ContentType,
+			Size:        int64(len(r.Content)),
+			Width:       int32(r.Width),
+			Height:      int32(r.Height),
+			StorageKey:  RenditionKey(image.StorageKey, r.Name, r.ContentType),
+		}
+		if err := p.opts.Blobs.Put(ctx, rendition.StorageKey, r.Content, r.ContentType); err != nil {
+			log.Error("Error storing the "+r.Name+" rendition of image "+image.ID.String(), err)
+			return
+		}
+		renditions = append(renditions, rendition)
+	}
+	p.save(ctx, image, renditions, models.RenditionsReady)
+}
+
+// save records the renditions, deleting their content again when the image
+// was deleted in the meantime.
+func (p *Pipeline) save(ctx context.Context, image models.ProductImage, renditions []models.ProductImageRendition, status string) {
+	err := p.opts.Save(ctx, image, renditions, status)
+	if errors.Is(err, ErrImageDeleted) {
+		for _, rendition := range renditions {
+			if err := p.opts.Blobs.Delete(ctx, rendition.StorageKey); err != nil {
+				log.Error("Error deleting the renditions of deleted image "+image.ID.String(), err)
+			}
+		}
+		return
+	}
+	if err != nil {
+		log.Error("Error recording the renditions of image "+image.ID.String(), err)
+	}
+}
@@ -0,0 +1,146 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Names of the renditions
+const (
+	RenditionThumbnail = "thumbnail"
+	RenditionMedium    = "medium"
+	RenditionLarge     = "large"
+)
+
+// Spec describes a rendition: the image scaled down to fit a square of
+// MaxSize pixels. Images are never scaled up.
+type Spec struct {
+	Name    string
+	MaxSize int
+}
+
+// Rendition is an image rendered for a Spec.
+type Rendition struct {
+	Name        string
+	Content     []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Render decodes an image and renders it for each spec, upright according to
+// its EXIF orientation. Opaque images are encoded as JPEG with the given
+// quality, images with transparency as PNG. Renditions are encoded afresh, so
+// they carry no metadata. Animated images are rendered from their first frame.
+// Images over MaxPixels are refused before they are decoded, whatever was
+// checked on upload.
+func Render(content []byte, orientation int, specs []Spec, quality int) ([]Rendition, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPixels(config); err != nil {
+		return nil, err
+	}
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	opaque := true
+	if o, ok := source.(interface{ Opaque() bool }); ok {
+		opaque = o.Opaque()
+	}
+
+	renditions := make([]Rendition, 0, len(specs))
+	for _, spec := range specs {
+		scaled := scale(source, spec.MaxSize)
+		rendered := orient(scaled, orientation)
+
+		var out bytes.Buffer
+		contentType := "image/png"
+		if opaque {
+			contentType = "image/jpeg"
+			err = jpeg.Encode(&out, rendered, &jpeg.Options{Quality: quality})
+		} else {
+			err = png.Encode(&out, rendered)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encoding the %s rendition: %w", spec.Name, err)
+		}
+		bounds := rendered.Bounds()
+		renditions = append(renditions, Rendition{
+			Name:        spec.Name,
+			Content:     out.Bytes(),
+			ContentType: contentType,
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+		})
+	}
+	return renditions, nil
+}
+
+// RenditionKey returns the blob key of a rendition of the image stored under
+// key.
+func RenditionKey(key string, name string, contentType string) string {
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		key = key[:dot]
+	}
+	return key + "_" + name + extensions[contentType]
+}
+
+// scale fits the image in a square of maxSize pixels, whatever its
+// orientation.
+func scale(source image.Image, maxSize int) *image.NRGBA {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	displayed := max(width, height)
+	if displayed > maxSize {
+		width = max(1, (width*maxSize+displayed/2)/displayed)
+		height = max(1, (height*maxSize+displayed/2)/displayed)
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), source, bounds, draw.Src, nil)
+	return scaled
+}
+
+// orient flips and rotates the image as its EXIF orientation requires.
+func orient(source *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= OrientationNormal || orientation > 8 {
+		return source
+	}
+	width, height := source.Bounds().Dx(), source.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation > 4 {
+		dstWidth, dstHeight = height, width
+	}
+	oriented := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = width-1-x, y
+			case 3: // Upside down
+				sx, sy = width-1-x, height-1-y
+			case 4: // Mirrored upside down
+				sx, sy = x, height-1-y
+			case 5: // Mirrored, rotated left
+				sx, sy = y, x
+			case 6: // Rotated left, turned right to display
+				sx, sy = y, height-1-x
+			case 7: // Mirrored, rotated right
+				sx, sy = width-1-y, height-1-x
+			case 8: // Rotated right, turned left to display
+				sx, sy = width-1-y, x
+			}
+			oriented.SetNRGBA(x, y, source.NRGBAAt(sx, sy))
+		}
+	}
+	return oriented
+}
@@ -0,0 +1,65 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+)
+
+var log = logger.NewLogger("product-service")
+
+// Hook releases the resources held by a component. It should return once the
+// component has been flushed or the context has expired.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager keeps track of the components which must be stopped when the
+// service shuts down, e.g. background workers and the database handle.
+type Manager struct {
+	mu    sync.Mutex
+	hooks []namedHook
+	done  bool
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Register adds a shutdown hook. Hooks run in reverse registration order, so
+// dependencies (like the database) should be registered before their users.
+func (m *Manager) Register(name string, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// Shutdown runs every registered hook once. All hooks are attempted even when
+// one of them fails; the first error is returned.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var firstErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		log.Info("Stopping " + hooks[i].name)
+		if err := hooks[i].hook(ctx); err != nil {
+			log.Error("Error stopping "+hooks[i].name, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", hooks[i].name, err)
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/grpcutil"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIdMetadataKey carries the correlation id between services. It is read
+// from the incoming metadata and echoed back in the response header.
+const RequestIdMetadataKey = "x-request-id"
+
+// UnaryServerInterceptor assigns a request id to every RPC, makes a logger
+// tagged with it available through FromContext and logs the outcome of the call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		requestId := requestIdFromMetadata(ctx)
+		if requestId == "" {
+			requestId = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIdMetadataKey, requestId))
+
+		attrs := []any{
+			slog.String("request_id", requestId),
+			slog.String("method", info.FullMethod),
+		}
+		if sellerId := security.SellerIdFromRequest(req); sellerId != "" {
+			attrs = append(attrs, slog.String("seller_id", sellerId))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		logger := FromContext(ctx).With(attrs...)
+		ctx = WithLogger(ctx, logger)
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := []any{
+			slog.String("peer", peerAddress(ctx)),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("code", code.String()),
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			fields = append(fields, slog.Any("request", redactMessage(req)))
+		}
+		if err != nil {
+			fields = append(fields, slog.String("error", err.Error()))
+			logger.ErrorContext(ctx, "rpc failed", fields...)
+		} else {
+			logger.InfoContext(ctx, "rpc completed", fields...)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the UnaryServerInterceptor of streaming RPCs.
+// Their messages are not logged.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := stream.Context()
+
+		requestId := requestIdFromMetadata(ctx)
+		if requestId == "" {
+			requestId = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIdMetadataKey, requestId))
+
+		attrs := []any{
+			slog.String("request_id", requestId),
+			slog.String("method", info.FullMethod),
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		logger := FromContext(ctx).With(attrs...)
+		ctx = WithLogger(ctx, logger)
+
+		err := handler(srv, grpcutil.WithContext(stream, ctx))
+
+		fields := []any{
+			slog.String("peer", peerAddress(ctx)),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("code", status.Code(err).String()),
+		}
+		if err != nil {
+			fields = append(fields, slog.String("error", err.Error()))
+			logger.ErrorContext(ctx, "rpc failed", fields...)
+		} else {
+			logger.InfoContext(ctx, "rpc completed", fields...)
+		}
+		return err
+	}
+}
+
+func requestIdFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequestIdMetadataKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/tittuvarghese/ss-go-product-service/constants"
+)
+
+type contextKey struct{}
+
+var base = New(os.Stdout, slog.LevelInfo)
+
+// New builds a JSON logger tagged with the service name.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	return slog.New(handler).With("service", constants.ModuleName)
+}
+
+// SetBase replaces the logger used when a context carries none.
+func SetBase(logger *slog.Logger) {
+	base = logger
+}
+
+// ParseLevel converts a level name such as "debug" or "warn", defaulting to info.
+func ParseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// WithLogger returns a copy of ctx carrying the request scoped logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request scoped logger, or the base logger outside
+// of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return base
+}
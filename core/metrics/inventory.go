@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// inventoryScrapeTimeout bounds the queries issued while serving a scrape.
+const inventoryScrapeTimeout = 5 * time.Second
+
+// InventoryStats is a snapshot of catalogue wide business figures.
+type InventoryStats struct {
+	Products   int64
+	OutOfStock int64
+}
+
+// InventoryFunc loads the current inventory figures.
+type InventoryFunc func(ctx context.Context) (InventoryStats, error)
+
+var (
+	productsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "inventory", "products"),
+		"Number of active products listed in the catalogue.",
+		nil, nil,
+	)
+	outOfStockDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "inventory", "out_of_stock_products"),
+		"Number of active products without any quantity left.",
+		nil, nil,
+	)
+)
+
+// inventoryCollector queries the inventory figures on every scrape so the
+// gauges never go stale and no background refresh is required.
+type inventoryCollector struct {
+	fetch InventoryFunc
+}
+
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- productsDesc
+	ch <- outOfStockDesc
+}
+
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryScrapeTimeout)
+	defer cancel()
+
+	stats, err := c.fetch(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(productsDesc, err)
+		ch <- prometheus.NewInvalidMetric(outOfStockDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(productsDesc, prometheus.GaugeValue, float64(stats.Products))
+	ch <- prometheus.MustNewConstMetric(outOfStockDesc, prometheus.GaugeValue, float64(stats.OutOfStock))
+}
+
+// RegisterInventory exposes the business gauges computed by fetch.
+func RegisterInventory(fetch InventoryFunc) error {
+	return Registry.Register(&inventoryCollector{fetch: fetch})
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tittuvarghese/ss-go-core/logger"
+)
+
+var log = logger.NewLogger("product-service")
+
+// NewServer builds the HTTP server exposing the registry on /metrics.
+func NewServer(port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// Serve runs the metrics server until it is shut down.
+func Serve(server *http.Server) {
+	log.Info("Metrics server is listening on " + server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Metrics server failed", err)
+	}
+}
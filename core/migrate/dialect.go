@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// lockName identifies the advisory lock held while migrating, so replicas
+// starting together do not apply the same migration twice.
+const lockName = "product-service-migrations"
+
+type dialect interface {
+	// lock blocks until the advisory lock is held by conn or timeout expires.
+	lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error
+	unlock(ctx context.Context, conn *sql.Conn) error
+	createHistoryTable() string
+	// placeholder returns the bind parameter syntax for the n-th argument.
+	placeholder(n int) string
+}
+
+func dialectFor(name string) (dialect, error) {
+	switch name {
+	case "mysql":
+		return mysqlDialect{}, nil
+	case "postgres":
+		return postgresDialect{}, nil
+	case "sqlite":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("migrations are not supported for dialect %q", name)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("timed out waiting for the migration lock")
+	}
+	return nil
+}
+
+func (mysqlDialect) unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+	return err
+}
+
+func (mysqlDialect) createHistoryTable() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    applied_at DATETIME     NOT NULL,
+    PRIMARY KEY (version)
+)`
+}
+
+func (mysqlDialect) placeholder(int) string {
+	return "?"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockName)
+	return err
+}
+
+func (postgresDialect) unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+	return err
+}
+
+func (postgresDialect) createHistoryTable() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (version)
+)`
+}
+
+func (postgresDialect) placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// sqliteDialect relies on SQLite serialising writers on the database file
+// instead of an advisory lock, which SQLite does not provide.
+type sqliteDialect struct{}
+
+func (sqliteDialect) lock(context.Context, *sql.Conn, time.Duration) error {
+	return nil
+}
+
+func (sqliteDialect) unlock(context.Context, *sql.Conn) error {
+	return nil
+}
+
+func (sqliteDialect) createHistoryTable() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER      NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    applied_at DATETIME     NOT NULL,
+    PRIMARY KEY (version)
+)`
+}
+
+func (sqliteDialect) placeholder(int) string {
+	return "?"
+}
@@ -0,0 +1,205 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+)
+
+var log = logger.NewLogger("product-service")
+
+// DefaultLockTimeout is how long a migration waits for another replica to
+// finish migrating.
+const DefaultLockTimeout = 2 * time.Minute
+
+type Options struct {
+	// Dialect selects the migration scripts and locking strategy.
+	Dialect string
+	// LockTimeout bounds the wait for the migration lock.
+	LockTimeout time.Duration
+}
+
+// Status reports whether a migration has been applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies the embedded migrations of one dialect and records them in
+// the schema_migrations table.
+type Migrator struct {
+	db          *sql.DB
+	dialect     dialect
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+// New loads the migrations found in the directory of fsys named after the dialect.
+func New(db *sql.DB, fsys fs.FS, opts Options) (*Migrator, error) {
+	d, err := dialectFor(opts.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(fsys, opts.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	lockTimeout := opts.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = DefaultLockTimeout
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations, lockTimeout: lockTimeout}, nil
+}
+
+// Up applies up to steps pending migrations in version order, or all of them
+// when steps is not positive.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			log.Info(fmt.Sprintf("Applying migration %d_%s", migration.Version, migration.Name))
+			insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)",
+				m.dialect.placeholder(1), m.dialect.placeholder(2), m.dialect.placeholder(3))
+			err := m.run(ctx, conn, migration.Up, insert, migration.Version, migration.Name, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the steps most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted, it has no down script", migration.Version, migration.Name)
+			}
+			log.Info(fmt.Sprintf("Reverting migration %d_%s", migration.Version, migration.Name))
+			remove := "DELETE FROM schema_migrations WHERE version = " + m.dialect.placeholder(1)
+			if err := m.run(ctx, conn, migration.Down, remove, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createHistoryTable()); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := done[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration lock, as
+// advisory locks belong to the session which acquired them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn, m.lockTimeout); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		if err := m.dialect.unlock(context.WithoutCancel(ctx), conn); err != nil {
+			log.Error("Error releasing migration lock", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createHistoryTable()); err != nil {
+		return fmt.Errorf("creating migration history table: %w", err)
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// run executes a script and updates the history in one transaction. Note that
+// MySQL commits DDL statements implicitly, so a failing script may be left
+// partially applied there.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, history string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, history, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
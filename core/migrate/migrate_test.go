@@ -0,0 +1,170 @@
+package migrate
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/migrations"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "statements",
+			script: "CREATE TABLE a (id INT);\n\nCREATE INDEX idx ON a (id);\n",
+			want:   []string{"CREATE TABLE a (id INT);", "CREATE INDEX idx ON a (id);"},
+		},
+		{
+			name:   "one line",
+			script: "DELETE FROM a; DELETE FROM b;",
+			want:   []string{"DELETE FROM a;", "DELETE FROM b;"},
+		},
+		{
+			name:   "multiple lines",
+			script: "UPDATE a\nSET id = 1\nWHERE id = 2;",
+			want:   []string{"UPDATE a\nSET id = 1\nWHERE id = 2;"},
+		},
+		{
+			name:   "no final semicolon",
+			script: "DELETE FROM a;\nDELETE FROM b\n",
+			want:   []string{"DELETE FROM a;", "DELETE FROM b"},
+		},
+		{
+			name:   "quoted semicolons",
+			script: "UPDATE a SET note = 'x;\ny; -- z';\nUPDATE a SET \"odd;name\" = 1;\nUPDATE `b;` SET c = 2;",
+			want:   []string{"UPDATE a SET note = 'x;\ny; -- z';", "UPDATE a SET \"odd;name\" = 1;", "UPDATE `b;` SET c = 2;"},
+		},
+		{
+			name:   "doubled quotes",
+			script: "UPDATE a SET note = 'it''s; fine';\nDELETE FROM a;",
+			want:   []string{"UPDATE a SET note = 'it''s; fine';", "DELETE FROM a;"},
+		},
+		{
+			name:   "line comments",
+			script: "-- Leading comment;\nDELETE FROM a; -- trailing comment\n  -- indented comment\nDELETE FROM b; -- 'unbalanced\nDELETE FROM c;",
+			want:   []string{"DELETE FROM a;", "DELETE FROM b;", "DELETE FROM c;"},
+		},
+		{
+			name:   "block comments",
+			script: "/* header; with\n   several lines */\nDELETE /* inline; */ FROM a;",
+			want:   []string{"DELETE   FROM a;"},
+		},
+		{
+			name:   "only comments",
+			script: "-- nothing to do\n/* really */\n",
+			want:   nil,
+		},
+	} {
+		if statements := splitStatements(test.script); !reflect.DeepEqual(statements, test.want) {
+			t.Errorf("%s: splitStatements = %q, want %q", test.name, statements, test.want)
+		}
+	}
+}
+
+func TestDialectsHaveTheSameMigrations(t *testing.T) {
+	sqlite, err := loadMigrations(migrations.Files, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dialect := range []string{"mysql", "postgres"} {
+		other, err := loadMigrations(migrations.Files, dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(other) != len(sqlite) {
+			t.Errorf("%s has %d migrations, sqlite %d", dialect, len(other), len(sqlite))
+			continue
+		}
+		for i, migration := range other {
+			if migration.Version != sqlite[i].Version || migration.Name != sqlite[i].Name || migration.Down == "" {
+				t.Errorf("%s migration %d_%s does not match %d_%s or has no down script", dialect, migration.Version, migration.Name, sqlite[i].Version, sqlite[i].Name)
+			}
+		}
+	}
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage, err := database.NewRelationalDatabase("sqlite://" + filepath.Join(t.TempDir(), "products.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	db, err := storage.SqlDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := New(db, migrations.Files, Options{Dialect: database.DialectSQLite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := len(migrator.migrations)
+
+	tables := func() []string {
+		rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+	applied := func() int {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, status := range statuses {
+			if status.Applied {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Every migration applies, one step at a time and then the rest
+	if done, err := migrator.Up(ctx, 1); err != nil || len(done) != 1 {
+		t.Fatalf("Up(1) applied %d migrations: %v", len(done), err)
+	}
+	if done, err := migrator.Up(ctx, 0); err != nil || len(done) != total-1 {
+		t.Fatalf("Up applied %d migrations: %v, want %d", len(done), err, total-1)
+	}
+	migrated := tables()
+	if done, err := migrator.Up(ctx, 0); err != nil || len(done) != 0 || applied() != total {
+		t.Fatalf("Up applied %d more migrations: %v", len(done), err)
+	}
+
+	// Every migration reverts, leaving only the history
+	if done, err := migrator.Down(ctx, total); err != nil || len(done) != total {
+		t.Fatalf("Down reverted %d migrations: %v, want %d", len(done), err, total)
+	}
+	if names := tables(); !reflect.DeepEqual(names, []string{"schema_migrations"}) || applied() != 0 {
+		t.Errorf("tables after reverting every migration = %q", names)
+	}
+
+	// And applies again to the same schema
+	if done, err := migrator.Up(ctx, 0); err != nil || len(done) != total {
+		t.Fatalf("Up after Down applied %d migrations: %v", len(done), err)
+	}
+	if names := tables(); !reflect.DeepEqual(names, migrated) {
+		t.Errorf("tables = %q, want %q", names, migrated)
+	}
+}
@@ -0,0 +1,148 @@
+package moderation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// Decisions of the rule engine
+const (
+	DecisionApprove = "approve"
+	DecisionReview  = "review"
+	DecisionReject  = "reject"
+)
+
+// Rule matches text of the checked product fields and adds Score to the risk
+// score of the product on a match.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Score   int
+}
+
+// KeywordRule returns a rule matching any of the words, as whole words and
+// regardless of case.
+func KeywordRule(name string, words []string, score int) Rule {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	return Rule{Name: name, Pattern: pattern, Score: score}
+}
+
+// CategoryRule restricts a category: products listed in it add Score, unless
+// their seller is allowed in the category.
+type CategoryRule struct {
+	Name           string
+	Category       string
+	Score          int
+	AllowedSellers map[string]bool
+}
+
+// Rules scores products against the rules and decides on them: products
+// scoring RejectScore or more are rejected, those scoring ReviewScore or more
+// go to manual review and the others are approved.
+type Rules struct {
+	Text        []Rule
+	Categories  []CategoryRule
+	ReviewScore int
+	RejectScore int
+}
+
+// Result is the outcome of the rules for a product.
+type Result struct {
+	Decision string
+	Score    int
+	Hits     []models.RuleHit
+}
+
+// Evaluate runs the rules against the name, type and category of a product.
+func (r *Rules) Evaluate(product models.Product) Result {
+	var result Result
+	fields := []struct{ name, value string }{
+		{"name", product.Name},
+		{"type", product.Type},
+		{"category", product.Category},
+	}
+	for _, rule := range r.Text {
+		for _, field := range fields {
+			match := rule.Pattern.FindString(field.value)
+			if match == "" {
+				continue
+			}
+			result.Hits = append(result.Hits, models.RuleHit{Rule: rule.Name, Field: field.name, Match: match, Score: rule.Score})
+			result.Score += rule.Score
+			// A rule counts once however many fields it matches
+			break
+		}
+	}
+	for _, rule := range r.Categories {
+		if !strings.EqualFold(product.Category, rule.Category) || rule.AllowedSellers[product.SellerId.String()] {
+			continue
+		}
+		result.Hits = append(result.Hits, models.RuleHit{Rule: rule.Name, Field: "category", Match: product.Category, Score: rule.Score})
+		result.Score += rule.Score
+	}
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		return result.Hits[i].Score > result.Hits[j].Score
+	})
+
+	switch {
+	case result.Score >= r.RejectScore:
+		result.Decision = DecisionReject
+	case result.Score >= r.ReviewScore:
+		result.Decision = DecisionReview
+	default:
+		result.Decision = DecisionApprove
+	}
+	return result
+}
+
+// Status returns the status a product in the current status moves to after
+// the decision, current being empty for a new product. Only products new,
+// pending review or active are decided on automatically; a rejected active
+// product is suspended and one sent to review leaves the catalogue until a
+// moderator approves it. Approved products go live without a moderator, as
+// enabling the rules delegates the approval of clean products to them.
+func (r Result) Status(current string) string {
+	switch current {
+	case "", models.ProductStatusPendingReview:
+		switch r.Decision {
+		case DecisionApprove:
+			return models.ProductStatusActive
+		case DecisionReject:
+			return models.ProductStatusRejected
+		default:
+			return models.ProductStatusPendingReview
+		}
+	case models.ProductStatusActive:
+		switch r.Decision {
+		case DecisionReject:
+			return models.ProductStatusSuspended
+		case DecisionReview:
+			return models.ProductStatusPendingReview
+		}
+	}
+	return current
+}
+
+// Reason explains the decision for the status reason of a product.
+func (r Result) Reason() string {
+	if len(r.Hits) == 0 {
+		return "automatically approved"
+	}
+	names := make([]string, len(r.Hits))
+	for i, hit := range r.Hits {
+		names[i] = hit.Rule
+	}
+	reason := "automatic " + r.Decision + ", matched rules: " + strings.Join(names, ", ")
+	if utf8.RuneCountInString(reason) > models.MaxStatusReasonLength {
+		reason = string([]rune(reason)[:models.MaxStatusReasonLength])
+	}
+	return reason
+}
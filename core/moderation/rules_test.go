@@ -0,0 +1,116 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+var allowedSeller = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+func testRules() *Rules {
+	return &Rules{
+		Text: []Rule{
+			KeywordRule("weapons", []string{"gun", "switchblade"}, 60),
+			KeywordRule("replicas", []string{"replica", "1:1 copy"}, 30),
+			{Name: "contact details", Pattern: regexp.MustCompile(`\+?\d{10,}`), Score: 20},
+		},
+		Categories: []CategoryRule{
+			{Name: "pharmacy", Category: "pharmacy", Score: 40, AllowedSellers: map[string]bool{allowedSeller.String(): true}},
+		},
+		ReviewScore: 30,
+		RejectScore: 60,
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		product  models.Product
+		score    int
+		decision string
+		hits     []string
+	}{
+		{"clean", models.Product{Name: "Steel kettle", Category: "kitchen"}, 0, DecisionApprove, nil},
+		{"below review", models.Product{Name: "Call 0123456789 for a kettle"}, 20, DecisionApprove, []string{"contact details"}},
+		// Scores at a threshold get its decision
+		{"at review", models.Product{Name: "Replica watch"}, 30, DecisionReview, []string{"replicas"}},
+		{"at reject", models.Product{Name: "Toy GUN"}, 60, DecisionReject, []string{"weapons"}},
+		{"hits add up", models.Product{Name: "1:1 copy watch, call +4412345678901"}, 50, DecisionReview, []string{"replicas", "contact details"}},
+		// Keywords only match whole words
+		{"within a word", models.Product{Name: "Gunmetal teapot", Type: "shotgun-grey"}, 0, DecisionApprove, nil},
+		// A rule counts once however many fields it matches
+		{"several fields", models.Product{Name: "replica", Type: "replica", Category: "replica"}, 30, DecisionReview, []string{"replicas"}},
+		{"type", models.Product{Name: "Knife", Type: "Switchblade"}, 60, DecisionReject, []string{"weapons"}},
+		{"restricted category", models.Product{Name: "Aspirin", Category: "Pharmacy"}, 40, DecisionReview, []string{"pharmacy"}},
+		{"allowed seller", models.Product{Name: "Aspirin", Category: "pharmacy", SellerId: allowedSeller}, 0, DecisionApprove, nil},
+		// Hits are ordered by decreasing score
+		{"sorted hits", models.Product{Name: "Replica gun 0123456789", Category: "pharmacy"}, 150, DecisionReject, []string{"weapons", "pharmacy", "replicas", "contact details"}},
+	} {
+		result := testRules().Evaluate(test.product)
+		var hits []string
+		for _, hit := range result.Hits {
+			hits = append(hits, hit.Rule)
+		}
+		if result.Score != test.score || result.Decision != test.decision || strings.Join(hits, ",") != strings.Join(test.hits, ",") {
+			t.Errorf("%s: %s with %d from %v, want %s with %d from %v", test.name, result.Decision, result.Score, hits, test.decision, test.score, test.hits)
+		}
+	}
+
+	result := testRules().Evaluate(models.Product{Name: "kettle", Type: "Toy Gun"})
+	if hit := result.Hits[0]; hit.Field != "type" || hit.Match != "Gun" || hit.Score != 60 {
+		t.Errorf("hit = %+v, want a match of Gun in type", hit)
+	}
+}
+
+func TestResultStatus(t *testing.T) {
+	for _, test := range []struct {
+		current  string
+		decision string
+		want     string
+	}{
+		// New and submitted products skip the moderator when approved
+		{"", DecisionApprove, models.ProductStatusActive},
+		{"", DecisionReview, models.ProductStatusPendingReview},
+		{"", DecisionReject, models.ProductStatusRejected},
+		{models.ProductStatusPendingReview, DecisionApprove, models.ProductStatusActive},
+		{models.ProductStatusPendingReview, DecisionReview, models.ProductStatusPendingReview},
+		{models.ProductStatusPendingReview, DecisionReject, models.ProductStatusRejected},
+		{models.ProductStatusActive, DecisionApprove, models.ProductStatusActive},
+		{models.ProductStatusActive, DecisionReview, models.ProductStatusPendingReview},
+		{models.ProductStatusActive, DecisionReject, models.ProductStatusSuspended},
+		// Other statuses are left to their transitions
+		{models.ProductStatusDraft, DecisionApprove, models.ProductStatusDraft},
+		{models.ProductStatusDraft, DecisionReject, models.ProductStatusDraft},
+		{models.ProductStatusRejected, DecisionApprove, models.ProductStatusRejected},
+		{models.ProductStatusSuspended, DecisionApprove, models.ProductStatusSuspended},
+		{models.ProductStatusSuspended, DecisionReview, models.ProductStatusSuspended},
+		{models.ProductStatusDiscontinued, DecisionReject, models.ProductStatusDiscontinued},
+	} {
+		if status := (Result{Decision: test.decision}).Status(test.current); status != test.want {
+			t.Errorf("Status(%q) after %s = %q, want %q", test.current, test.decision, status, test.want)
+		}
+	}
+}
+
+func TestResultReason(t *testing.T) {
+	if reason := (Result{Decision: DecisionApprove}).Reason(); reason != "automatically approved" {
+		t.Errorf("Reason() = %q", reason)
+	}
+	result := testRules().Evaluate(models.Product{Name: "Replica gun"})
+	if reason, want := result.Reason(), "automatic reject, matched rules: weapons, replicas"; reason != want {
+		t.Errorf("Reason() = %q, want %q", reason, want)
+	}
+
+	var hits []models.RuleHit
+	for i := 0; i < 100; i++ {
+		hits = append(hits, models.RuleHit{Rule: "prohibited keyword"})
+	}
+	if reason := (Result{Decision: DecisionReject, Hits: hits}).Reason(); utf8.RuneCountInString(reason) != models.MaxStatusReasonLength {
+		t.Errorf("Reason() is %d characters long, want %d", utf8.RuneCountInString(reason), models.MaxStatusReasonLength)
+	}
+}
@@ -0,0 +1,133 @@
+// Package money represents prices as integer amounts of the minor unit of a
+// currency, so totals add up without floating point rounding.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const nanosPerUnit = 1_000_000_000
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrPrecision       = errors.New("amount is more precise than the minor unit of its currency")
+	ErrOutOfRange      = errors.New("amount is out of range")
+)
+
+// minorUnits holds the number of decimals of the minor unit of the supported
+// ISO 4217 currencies.
+var minorUnits = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BRL": 2,
+	"CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2,
+	"EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KES": 2, "KRW": 0, "KWD": 3,
+	"LKR": 2, "MAD": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PEN": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2,
+	"SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2,
+	"UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// MinorUnits returns the number of decimals of the minor unit of a currency,
+// e.g. 2 for USD cents and 0 for JPY.
+func MinorUnits(currency string) (int, error) {
+	digits, ok := minorUnits[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+	return digits, nil
+}
+
+// Money is an amount in the minor unit of a currency, e.g. 1999 USD is
+// $19.99. The zero value has no currency and is not valid.
+type Money struct {
+	Currency string `gorm:"size:3;not null" json:"currency"`
+	Amount   int64  `gorm:"not null" json:"amount"`
+}
+
+// New returns an amount of minor units of a currency.
+func New(currency string, amount int64) (Money, error) {
+	if _, err := MinorUnits(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Currency: currency, Amount: amount}, nil
+}
+
+// FromUnits converts an amount given as whole units and nano units, the
+// layout of google.type.Money. Amounts with more precision than the minor
+// unit of the currency are rejected rather than rounded.
+func FromUnits(currency string, units int64, nanos int32) (Money, error) {
+	digits, err := MinorUnits(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit || (units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return Money{}, fmt.Errorf("%w: nanos %d do not match units %d", ErrOutOfRange, nanos, units)
+	}
+
+	nanosPerMinor := int32(nanosPerUnit / pow10(digits))
+	if nanos%nanosPerMinor != 0 {
+		return Money{}, fmt.Errorf("%w: %s has %d decimals", ErrPrecision, currency, digits)
+	}
+	scale := pow10(digits)
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, ErrOutOfRange
+	}
+	// The minor units may still overflow at the edge of the range
+	minor := int64(nanos / nanosPerMinor)
+	if (minor > 0 && units*scale > math.MaxInt64-minor) || (minor < 0 && units*scale < math.MinInt64-minor) {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Currency: currency, Amount: units*scale + minor}, nil
+}
+
+// Units splits the amount into whole units and nano units, the layout of
+// google.type.Money.
+func (m Money) Units() (int64, int32) {
+	digits, err := MinorUnits(m.Currency)
+	if err != nil {
+		return 0, 0
+	}
+	scale := pow10(digits)
+	return m.Amount / scale, int32(m.Amount%scale) * int32(nanosPerUnit/scale)
+}
+
+// Validate reports whether the currency is supported.
+func (m Money) Validate() error {
+	_, err := MinorUnits(m.Currency)
+	return err
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// String formats the amount with the decimals of its currency, e.g. "19.99 USD".
+func (m Money) String() string {
+	digits, err := MinorUnits(m.Currency)
+	if err != nil || digits == 0 {
+		return strconv.FormatInt(m.Amount, 10) + " " + m.Currency
+	}
+	sign := ""
+	// Unsigned, so the smallest amount can be negated
+	amount := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := uint64(pow10(digits))
+	fraction := strconv.FormatUint(amount%scale, 10)
+	return fmt.Sprintf("%s%d.%s%s %s", sign, amount/scale, strings.Repeat("0", digits-len(fraction)), fraction, m.Currency)
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
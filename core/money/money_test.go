@@ -0,0 +1,106 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMinorUnits(t *testing.T) {
+	for currency, want := range map[string]int{"USD": 2, "EUR": 2, "JPY": 0, "KRW": 0, "BHD": 3, "KWD": 3} {
+		if digits, err := MinorUnits(currency); err != nil || digits != want {
+			t.Errorf("MinorUnits(%s) = %d, %v, want %d", currency, digits, err, want)
+		}
+	}
+	for _, currency := range []string{"", "usd", "XXX", "EURO"} {
+		if _, err := MinorUnits(currency); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("MinorUnits(%q) = %v, want ErrUnknownCurrency", currency, err)
+		}
+	}
+}
+
+func TestFromUnits(t *testing.T) {
+	for _, test := range []struct {
+		currency string
+		units    int64
+		nanos    int32
+		want     int64
+		err      error
+	}{
+		{currency: "USD", units: 19, nanos: 990_000_000, want: 1999},
+		{currency: "USD", units: 0, nanos: 10_000_000, want: 1},
+		{currency: "USD", units: -19, nanos: -990_000_000, want: -1999},
+		{currency: "USD", units: 0, nanos: -500_000_000, want: -50},
+		{currency: "JPY", units: 1500, want: 1500},
+		{currency: "BHD", units: 1, nanos: 234_000_000, want: 1234},
+		{currency: "USD", units: 0, want: 0},
+		// Finer than the minor unit
+		{currency: "USD", units: 1, nanos: 5_000_000, err: ErrPrecision},
+		{currency: "JPY", units: 1, nanos: 500_000_000, err: ErrPrecision},
+		{currency: "BHD", units: 0, nanos: 100_000, err: ErrPrecision},
+		// Nanos out of range or of the other sign than units
+		{currency: "USD", units: 1, nanos: 1_000_000_000, err: ErrOutOfRange},
+		{currency: "USD", units: 0, nanos: -1_000_000_000, err: ErrOutOfRange},
+		{currency: "USD", units: 1, nanos: -10_000_000, err: ErrOutOfRange},
+		{currency: "USD", units: -1, nanos: 10_000_000, err: ErrOutOfRange},
+		// Overflow of the minor units
+		{currency: "USD", units: math.MaxInt64 / 100, want: math.MaxInt64 / 100 * 100},
+		{currency: "USD", units: math.MaxInt64 / 100, nanos: 70_000_000, want: math.MaxInt64},
+		{currency: "USD", units: math.MaxInt64 / 100, nanos: 990_000_000, err: ErrOutOfRange},
+		{currency: "USD", units: math.MaxInt64/100 + 1, err: ErrOutOfRange},
+		{currency: "USD", units: math.MinInt64 / 100, nanos: -80_000_000, want: math.MinInt64},
+		{currency: "USD", units: math.MinInt64 / 100, nanos: -990_000_000, err: ErrOutOfRange},
+		{currency: "JPY", units: math.MaxInt64, want: math.MaxInt64},
+		{currency: "XXX", units: 1, err: ErrUnknownCurrency},
+	} {
+		m, err := FromUnits(test.currency, test.units, test.nanos)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("FromUnits(%s, %d, %d) = %v, %v, want %v", test.currency, test.units, test.nanos, m, err, test.err)
+			}
+			continue
+		}
+		if err != nil || m.Amount != test.want || m.Currency != test.currency {
+			t.Errorf("FromUnits(%s, %d, %d) = %+v, %v, want %d", test.currency, test.units, test.nanos, m, err, test.want)
+		}
+	}
+}
+
+func TestUnitsRoundTrip(t *testing.T) {
+	for _, m := range []Money{
+		{Currency: "USD", Amount: 1999},
+		{Currency: "USD", Amount: -1999},
+		{Currency: "USD", Amount: -5},
+		{Currency: "JPY", Amount: 1500},
+		{Currency: "BHD", Amount: 1234},
+		{Currency: "USD", Amount: math.MaxInt64},
+		{Currency: "USD", Amount: math.MinInt64},
+	} {
+		units, nanos := m.Units()
+		back, err := FromUnits(m.Currency, units, nanos)
+		if err != nil || back != m {
+			t.Errorf("%+v: Units = %d, %d, converted back to %+v, %v", m, units, nanos, back, err)
+		}
+	}
+	if units, nanos := (Money{Currency: "USD", Amount: -1999}).Units(); units != -19 || nanos != -990_000_000 {
+		t.Errorf("Units of -19.99 USD = %d, %d", units, nanos)
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, test := range []struct {
+		money Money
+		want  string
+	}{
+		{Money{Currency: "USD", Amount: 1999}, "19.99 USD"},
+		{Money{Currency: "USD", Amount: 5}, "0.05 USD"},
+		{Money{Currency: "USD", Amount: -1999}, "-19.99 USD"},
+		{Money{Currency: "JPY", Amount: 1500}, "1500 JPY"},
+		{Money{Currency: "BHD", Amount: 1005}, "1.005 BHD"},
+		{Money{Currency: "USD", Amount: math.MinInt64}, "-92233720368547758.08 USD"},
+	} {
+		if got := test.money.String(); got != test.want {
+			t.Errorf("String(%+v) = %q, want %q", test.money, got, test.want)
+		}
+	}
+}
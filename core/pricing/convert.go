@@ -0,0 +1,71 @@
+// Package pricing converts and rounds prices between currencies.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+var log = logger.NewLogger("product-service")
+
+var ErrNoRate = errors.New("no exchange rate")
+
+// ParseRate parses a decimal exchange rate, e.g. "0.9234". Rates are kept as
+// exact fractions so conversions only round once, to the target minor unit.
+func ParseRate(rate string) (*big.Rat, error) {
+	parsed, ok := new(big.Rat).SetString(rate)
+	if !ok {
+		return nil, fmt.Errorf("invalid exchange rate %q", rate)
+	}
+	if parsed.Sign() <= 0 {
+		return nil, fmt.Errorf("exchange rate %q must be positive", rate)
+	}
+	return parsed, nil
+}
+
+// Convert converts an amount into currency, where one unit of the currency of
+// amount is worth rate units of currency. The result is rounded half away
+// from zero to the minor unit of currency.
+func Convert(amount money.Money, currency string, rate *big.Rat) (money.Money, error) {
+	fromDigits, err := money.MinorUnits(amount.Currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+	toDigits, err := money.MinorUnits(currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	converted := new(big.Rat).SetInt64(amount.Amount)
+	converted.Mul(converted, rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(toDigits), pow10(fromDigits)))
+
+	result, err := roundHalfAway(converted)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.Money{Currency: currency, Amount: result}, nil
+}
+
+func roundHalfAway(value *big.Rat) (int64, error) {
+	num := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	if !quotient.IsInt64() {
+		return 0, money.ErrOutOfRange
+	}
+	return quotient.Int64(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
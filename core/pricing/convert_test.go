@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+func TestParseRate(t *testing.T) {
+	for _, valid := range []string{"0.9234", "90.12", "1", "149.5", "0.0067"} {
+		if _, err := ParseRate(valid); err != nil {
+			t.Errorf("ParseRate(%q): %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "abc", "0", "-1.5", "0.00"} {
+		if rate, err := ParseRate(invalid); err == nil {
+			t.Errorf("ParseRate(%q) = %v, want an error", invalid, rate)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for _, test := range []struct {
+		amount   money.Money
+		currency string
+		rate     string
+		want     int64
+		err      error
+	}{
+		{amount: money.Money{Currency: "USD", Amount: 1000}, currency: "EUR", rate: "0.9234", want: 923},
+		// Between currencies of different minor units
+		{amount: money.Money{Currency: "USD", Amount: 1999}, currency: "JPY", rate: "149.5", want: 2989},
+		{amount: money.Money{Currency: "JPY", Amount: 100}, currency: "USD", rate: "0.0067", want: 67},
+		{amount: money.Money{Currency: "USD", Amount: 1000}, currency: "BHD", rate: "0.376", want: 3760},
+		{amount: money.Money{Currency: "BHD", Amount: 1}, currency: "USD", rate: "2.65", want: 0},
+		// Ties round half away from zero
+		{amount: money.Money{Currency: "USD", Amount: 1}, currency: "EUR", rate: "0.5", want: 1},
+		{amount: money.Money{Currency: "USD", Amount: -1}, currency: "EUR", rate: "0.5", want: -1},
+		{amount: money.Money{Currency: "USD", Amount: 3}, currency: "EUR", rate: "0.5", want: 2},
+		{amount: money.Money{Currency: "USD", Amount: 0}, currency: "EUR", rate: "0.9", want: 0},
+		{amount: money.Money{Currency: "USD", Amount: math.MaxInt64}, currency: "EUR", rate: "2", err: money.ErrOutOfRange},
+		{amount: money.Money{Currency: "USD", Amount: 100}, currency: "XXX", rate: "2", err: money.ErrUnknownCurrency},
+		{amount: money.Money{Currency: "XXX", Amount: 100}, currency: "USD", rate: "2", err: money.ErrUnknownCurrency},
+	} {
+		rate, err := ParseRate(test.rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		converted, err := Convert(test.amount, test.currency, rate)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("Convert(%v, %s, %s) = %v, %v, want %v", test.amount, test.currency, test.rate, converted, err, test.err)
+			}
+			continue
+		}
+		if err != nil || converted.Currency != test.currency || converted.Amount != test.want {
+			t.Errorf("Convert(%v, %s, %s) = %+v, %v, want %d", test.amount, test.currency, test.rate, converted, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package pricing
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"gopkg.in/yaml.v3"
+)
+
+// Rate states that one unit of Base is worth Rate units of Quote.
+type Rate struct {
+	Base  string `yaml:"base"`
+	Quote string `yaml:"quote"`
+	Rate  string `yaml:"rate"`
+}
+
+// Validate reports whether both currencies are supported and the rate is a
+// positive decimal.
+func (r Rate) Validate() error {
+	if _, err := money.MinorUnits(r.Base); err != nil {
+		return err
+	}
+	if _, err := money.MinorUnits(r.Quote); err != nil {
+		return err
+	}
+	if r.Base == r.Quote {
+		return fmt.Errorf("base and quote currency are both %s", r.Base)
+	}
+	_, err := ParseRate(r.Rate)
+	return err
+}
+
+// ReadRatesFile reads and validates exchange rates from a YAML file of the
+// form
+//
+//	rates:
+//	  - base: EUR
+//	    quote: INR
+//	    rate: "90.12"
+func ReadRatesFile(path string) ([]Rate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rates: %w", err)
+	}
+	var file struct {
+		Rates []Rate `yaml:"rates"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parsing exchange rates %s: %w", path, err)
+	}
+	for i, rate := range file.Rates {
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("exchange rate %d of %s: %w", i, path, err)
+		}
+	}
+	return file.Rates, nil
+}
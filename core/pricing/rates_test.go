@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRateValidate(t *testing.T) {
+	if err := (Rate{Base: "EUR", Quote: "INR", Rate: "90.12"}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for _, rate := range []Rate{
+		{Base: "EUR", Quote: "EUR", Rate: "1"},
+		{Base: "EUR", Quote: "XXX", Rate: "1.2"},
+		{Base: "xxx", Quote: "USD", Rate: "1.2"},
+		{Base: "EUR", Quote: "USD", Rate: "0"},
+		{Base: "EUR", Quote: "USD", Rate: "1,2"},
+	} {
+		if err := rate.Validate(); err == nil {
+			t.Errorf("%+v is valid, want an error", rate)
+		}
+	}
+}
+
+func TestReadRatesFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rates, err := ReadRatesFile(write("rates.yaml", `
+rates:
+  - base: EUR
+    quote: INR
+    rate: "90.12"
+  - base: USD
+    quote: EUR
+    rate: "0.9234"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 2 || rates[0] != (Rate{Base: "EUR", Quote: "INR", Rate: "90.12"}) {
+		t.Errorf("ReadRatesFile = %+v", rates)
+	}
+
+	for name, content := range map[string]string{
+		"invalid.yaml": "rates:\n  - base: EUR\n    quote: EUR\n    rate: \"1\"\n",
+		"unknown.yaml": "rates:\n  - base: EUR\n    quote: USD\n    rate: \"1.1\"\n    date: 2024-01-01\n",
+		"broken.yaml":  "rates: [",
+	} {
+		if _, err := ReadRatesFile(write(name, content)); err == nil {
+			t.Errorf("ReadRatesFile(%s) succeeded, want an error", name)
+		}
+	}
+	if _, err := ReadRatesFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("ReadRatesFile of a missing file succeeded")
+	}
+}
@@ -0,0 +1,81 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+// Rounding modes of a RoundingRule
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// RoundingRule turns converted prices into price points of a currency. Prices
+// are moved to an amount whose remainder modulo Increment is Ending, both in
+// minor units: Increment 100 with Ending 99 gives prices ending in .99 for
+// USD, Increment 100 with Ending 0 gives whole rupees for INR.
+type RoundingRule struct {
+	Increment int64
+	Ending    int64
+	Mode      string
+}
+
+// Validate reports whether the rule can be applied.
+func (r RoundingRule) Validate() error {
+	if r.Increment < 1 {
+		return fmt.Errorf("increment must be at least 1")
+	}
+	if r.Ending < 0 || r.Ending >= r.Increment {
+		return fmt.Errorf("ending must be between 0 and increment - 1")
+	}
+	switch r.Mode {
+	case RoundNearest, RoundUp, RoundDown:
+		return nil
+	default:
+		return fmt.Errorf("unknown rounding mode %q", r.Mode)
+	}
+}
+
+// Rounding holds the rounding rule of each currency. Currencies without a rule
+// keep the amount as converted.
+type Rounding map[string]RoundingRule
+
+// Apply rounds a converted amount according to the rule of its currency.
+// Amounts below the smallest price point are rounded up to it.
+func (r Rounding) Apply(amount money.Money) money.Money {
+	rule, ok := r[amount.Currency]
+	if !ok || amount.Amount <= 0 {
+		return amount
+	}
+
+	// The price points around the amount
+	down := amount.Amount - mod(amount.Amount-rule.Ending, rule.Increment)
+	up := down
+	if down != amount.Amount {
+		up = down + rule.Increment
+	}
+	if down <= 0 {
+		down = up
+	}
+
+	switch rule.Mode {
+	case RoundUp:
+		amount.Amount = up
+	case RoundDown:
+		amount.Amount = down
+	default:
+		if amount.Amount-down < up-amount.Amount {
+			amount.Amount = down
+		} else {
+			amount.Amount = up
+		}
+	}
+	return amount
+}
+
+func mod(a, b int64) int64 {
+	return ((a % b) + b) % b
+}
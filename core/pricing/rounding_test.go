@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+func TestRoundingRuleValidate(t *testing.T) {
+	for _, rule := range []RoundingRule{
+		{Increment: 100, Ending: 99, Mode: RoundNearest},
+		{Increment: 1, Ending: 0, Mode: RoundUp},
+		{Increment: 10, Ending: 0, Mode: RoundDown},
+	} {
+		if err := rule.Validate(); err != nil {
+			t.Errorf("%+v: %v", rule, err)
+		}
+	}
+	for _, rule := range []RoundingRule{
+		{Increment: 0, Ending: 0, Mode: RoundNearest},
+		{Increment: 100, Ending: 100, Mode: RoundNearest},
+		{Increment: 100, Ending: -1, Mode: RoundNearest},
+		{Increment: 100, Ending: 99, Mode: "even"},
+	} {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("%+v is valid, want an error", rule)
+		}
+	}
+}
+
+func TestRoundingApply(t *testing.T) {
+	rounding := Rounding{
+		// Psychological .99 prices
+		"USD": {Increment: 100, Ending: 99, Mode: RoundNearest},
+		"EUR": {Increment: 100, Ending: 99, Mode: RoundUp},
+		"GBP": {Increment: 100, Ending: 99, Mode: RoundDown},
+		// Whole rupees
+		"INR": {Increment: 100, Ending: 0, Mode: RoundNearest},
+		// Zero-decimal currencies, in tens and hundreds of yen
+		"JPY": {Increment: 10, Ending: 0, Mode: RoundNearest},
+		"KRW": {Increment: 100, Ending: 0, Mode: RoundDown},
+	}
+	for _, test := range []struct {
+		amount money.Money
+		want   int64
+	}{
+		{money.Money{Currency: "USD", Amount: 1234}, 1199},
+		{money.Money{Currency: "USD", Amount: 1260}, 1299},
+		{money.Money{Currency: "USD", Amount: 1199}, 1199},
+		// Ties go to the higher price point
+		{money.Money{Currency: "USD", Amount: 1249}, 1299},
+		// Below the smallest price point
+		{money.Money{Currency: "USD", Amount: 50}, 99},
+		{money.Money{Currency: "USD", Amount: 1}, 99},
+		{money.Money{Currency: "EUR", Amount: 1200}, 1299},
+		{money.Money{Currency: "EUR", Amount: 1299}, 1299},
+		{money.Money{Currency: "GBP", Amount: 1298}, 1199},
+		{money.Money{Currency: "GBP", Amount: 98}, 99},
+		{money.Money{Currency: "INR", Amount: 12345}, 12300},
+		{money.Money{Currency: "INR", Amount: 12350}, 12400},
+		{money.Money{Currency: "JPY", Amount: 1234}, 1230},
+		{money.Money{Currency: "JPY", Amount: 1235}, 1240},
+		{money.Money{Currency: "JPY", Amount: 4}, 10},
+		{money.Money{Currency: "KRW", Amount: 19999}, 19900},
+		// Free, negative and unruled amounts are kept
+		{money.Money{Currency: "USD", Amount: 0}, 0},
+		{money.Money{Currency: "USD", Amount: -1234}, -1234},
+		{money.Money{Currency: "CHF", Amount: 1234}, 1234},
+	} {
+		if got := rounding.Apply(test.amount); got.Amount != test.want || got.Currency != test.amount.Currency {
+			t.Errorf("Apply(%v) = %v, want %d", test.amount, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// Targets of a price schedule
+const (
+	TargetProduct  = "product"
+	TargetSeller   = "seller"
+	TargetCategory = "category"
+)
+
+// Kinds of price schedule
+const (
+	KindPercentOff = "percent_off"
+	KindAmountOff  = "amount_off"
+	KindFixedPrice = "fixed_price"
+)
+
+// specificity ranks the targets for conflict resolution, see Resolve.
+var specificity = map[string]int{TargetProduct: 3, TargetSeller: 2, TargetCategory: 1}
+
+// ValidateSchedule reports whether a schedule can be stored.
+func ValidateSchedule(schedule models.PriceSchedule) error {
+	if schedule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, ok := specificity[schedule.TargetType]; !ok {
+		return fmt.Errorf("unknown target type %q", schedule.TargetType)
+	}
+	if schedule.TargetId == "" {
+		return fmt.Errorf("target id is required")
+	}
+	switch schedule.Kind {
+	case KindPercentOff:
+		if schedule.PercentOff <= 0 || schedule.PercentOff > 10000 {
+			return fmt.Errorf("percent off must be between 1 and 10000 basis points")
+		}
+	case KindAmountOff, KindFixedPrice:
+		if err := schedule.Amount.Validate(); err != nil {
+			return err
+		}
+		if schedule.Amount.Amount < 0 || (schedule.Kind == KindAmountOff && schedule.Amount.Amount == 0) {
+			return fmt.Errorf("amount must be positive")
+		}
+		if schedule.Kind == KindFixedPrice && schedule.TargetType != TargetProduct {
+			return fmt.Errorf("fixed prices can only target a product")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", schedule.Kind)
+	}
+	if !schedule.EndAt.After(schedule.StartAt) {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+// applies reports whether the schedule targets the product and can change a
+// price in the currency of price. Schedules with an amount only apply to
+// prices in its currency.
+func applies(schedule models.PriceSchedule, product models.Product, at time.Time) bool {
+	if at.Before(schedule.StartAt) || !at.Before(schedule.EndAt) {
+		return false
+	}
+	if schedule.Kind != KindPercentOff && schedule.Amount.Currency != product.Price.Currency {
+		return false
+	}
+	switch schedule.TargetType {
+	case TargetProduct:
+		return schedule.TargetId == product.ID.String()
+	case TargetSeller:
+		return schedule.TargetId == product.SellerId.String()
+	case TargetCategory:
+		return schedule.TargetId == product.Category
+	}
+	return false
+}
+
+// apply returns price with the schedule applied, never below zero. Percentages
+// are rounded half up to the minor unit.
+func apply(schedule models.PriceSchedule, price money.Money) money.Money {
+	switch schedule.Kind {
+	case KindPercentOff:
+		price.Amount -= (price.Amount*schedule.PercentOff + 5000) / 10000
+	case KindAmountOff:
+		price.Amount -= schedule.Amount.Amount
+	case KindFixedPrice:
+		price.Amount = schedule.Amount.Amount
+	}
+	price.Amount = max(price.Amount, 0)
+	return price
+}
+
+// Resolve applies the price schedule in effect for the product at the given
+// time. Schedules never stack; when several apply, the one with the highest
+// priority wins, then the one with the most specific target (product, then
+// seller, then category), then the one giving the lowest price and finally
+// the one which started last. The product is returned unchanged when no
+// schedule applies.
+func Resolve(schedules []models.PriceSchedule, product models.Product, at time.Time) models.Product {
+	var winner *models.PriceSchedule
+	var best money.Money
+	for i := range schedules {
+		schedule := &schedules[i]
+		if !applies(*schedule, product, at) {
+			continue
+		}
+		price := apply(*schedule, product.Price)
+		if winner == nil || wins(*schedule, price, *winner, best) {
+			winner, best = schedule, price
+		}
+	}
+	if winner == nil || best == product.Price {
+		return product
+	}
+
+	product.Sale = &models.Sale{ScheduleId: winner.ID, OriginalPrice: product.Price, EndsAt: winner.EndAt}
+	product.Price = best
+	return product
+}
+
+func wins(candidate models.PriceSchedule, price money.Money, current models.PriceSchedule, currentPrice money.Money) bool {
+	if candidate.Priority != current.Priority {
+		return candidate.Priority > current.Priority
+	}
+	if specificity[candidate.TargetType] != specificity[current.TargetType] {
+		return specificity[candidate.TargetType] > specificity[current.TargetType]
+	}
+	if price.Amount != currentPrice.Amount {
+		return price.Amount < currentPrice.Amount
+	}
+	return candidate.StartAt.After(current.StartAt)
+}
+
+// ScheduleLoader reads the price schedules which have not ended yet.
+type ScheduleLoader func(ctx context.Context) ([]models.PriceSchedule, error)
+
+// Schedules keeps the price schedules in process so they can be applied to
+// every read without a query. They are reloaded every interval, and right
+// away through Refresh after a change made by this replica. A nil *Schedules
+// holds no schedules.
+type Schedules struct {
+	load     ScheduleLoader
+	current  atomic.Pointer[[]models.PriceSchedule]
+	refresh  sync.Mutex
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSchedules loads the schedules once and keeps reloading them in the
+// background until Close is called.
+func NewSchedules(ctx context.Context, load ScheduleLoader, interval time.Duration) (*Schedules, error) {
+	s := &Schedules{
+		load:     load,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+// Refresh reloads the schedules.
+func (s *Schedules) Refresh(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	s.refresh.Lock()
+	defer s.refresh.Unlock()
+
+	schedules, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	s.current.Store(&schedules)
+	return nil
+}
+
+// Apply resolves the schedule in effect now for each product.
+func (s *Schedules) Apply(products []models.Product) []models.Product {
+	if s == nil {
+		return products
+	}
+	schedules := *s.current.Load()
+	if len(schedules) == 0 {
+		return products
+	}
+	now := time.Now()
+	applied := make([]models.Product, len(products))
+	for i, product := range products {
+		applied[i] = Resolve(schedules, product, now)
+	}
+	return applied
+}
+
+// Close stops reloading the schedules.
+func (s *Schedules) Close(ctx context.Context) error {
+	close(s.stop)
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Schedules) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			if err := s.Refresh(ctx); err != nil {
+				log.Error("Error reloading price schedules", err)
+			}
+			cancel()
+		}
+	}
+}
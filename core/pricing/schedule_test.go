@@ -0,0 +1,223 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+var (
+	scheduleAt = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	product    = models.Product{
+		ID:       uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		SellerId: uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		Category: "books",
+		Price:    money.Money{Currency: "USD", Amount: 10000},
+	}
+)
+
+// schedule returns a schedule of the kind, running a day either side of
+// scheduleAt.
+func schedule(targetType string, kind string, off int64) models.PriceSchedule {
+	result := models.PriceSchedule{
+		ID:         uuid.New(),
+		Name:       "sale",
+		TargetType: targetType,
+		Kind:       kind,
+		StartAt:    scheduleAt.Add(-24 * time.Hour),
+		EndAt:      scheduleAt.Add(24 * time.Hour),
+	}
+	switch targetType {
+	case TargetProduct:
+		result.TargetId = product.ID.String()
+	case TargetSeller:
+		result.TargetId = product.SellerId.String()
+	case TargetCategory:
+		result.TargetId = product.Category
+	}
+	if kind == KindPercentOff {
+		result.PercentOff = off
+	} else {
+		result.Amount = money.Money{Currency: "USD", Amount: off}
+	}
+	return result
+}
+
+func TestResolveWindow(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		startAt time.Time
+		endAt   time.Time
+		applies bool
+	}{
+		{"running", scheduleAt.Add(-time.Hour), scheduleAt.Add(time.Hour), true},
+		// The start is inclusive and the end exclusive
+		{"starting", scheduleAt, scheduleAt.Add(time.Hour), true},
+		{"ending", scheduleAt.Add(-time.Hour), scheduleAt, false},
+		{"about to end", scheduleAt.Add(-time.Hour), scheduleAt.Add(time.Nanosecond), true},
+		{"about to start", scheduleAt.Add(time.Nanosecond), scheduleAt.Add(time.Hour), false},
+		{"ended", scheduleAt.Add(-2 * time.Hour), scheduleAt.Add(-time.Hour), false},
+		// Schedules have no open end, a distant one stands for it
+		{"distant end", scheduleAt.Add(-time.Hour), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"no end", scheduleAt.Add(-time.Hour), time.Time{}, false},
+	} {
+		sale := schedule(TargetProduct, KindPercentOff, 1000)
+		sale.StartAt, sale.EndAt = test.startAt, test.endAt
+		resolved := Resolve([]models.PriceSchedule{sale}, product, scheduleAt)
+		if applied := resolved.Sale != nil; applied != test.applies {
+			t.Errorf("%s: applied = %v, want %v", test.name, applied, test.applies)
+			continue
+		}
+		if test.applies && (resolved.Price.Amount != 9000 || resolved.Sale.EndsAt != test.endAt || resolved.Sale.OriginalPrice != product.Price) {
+			t.Errorf("%s: price %v, sale %+v", test.name, resolved.Price, resolved.Sale)
+		}
+		if !test.applies && resolved.Price != product.Price {
+			t.Errorf("%s: price %v, want %v", test.name, resolved.Price, product.Price)
+		}
+	}
+}
+
+func TestResolveOverlapping(t *testing.T) {
+	priority := func(sale models.PriceSchedule, priority int32) models.PriceSchedule {
+		sale.Priority = priority
+		return sale
+	}
+	started := func(sale models.PriceSchedule, startAt time.Time) models.PriceSchedule {
+		sale.StartAt = startAt
+		return sale
+	}
+	latest := started(schedule(TargetCategory, KindPercentOff, 1000), scheduleAt.Add(-time.Hour))
+
+	for _, test := range []struct {
+		name      string
+		schedules []models.PriceSchedule
+		want      int64
+		winner    int
+	}{
+		{
+			name:      "the most specific target wins",
+			schedules: []models.PriceSchedule{schedule(TargetCategory, KindPercentOff, 5000), schedule(TargetProduct, KindPercentOff, 500), schedule(TargetSeller, KindPercentOff, 2000)},
+			want:      9500,
+			winner:    1,
+		},
+		{
+			name:      "the highest priority wins over the target",
+			schedules: []models.PriceSchedule{schedule(TargetProduct, KindPercentOff, 500), priority(schedule(TargetCategory, KindPercentOff, 1000), 1)},
+			want:      9000,
+			winner:    1,
+		},
+		{
+			name:      "the highest priority wins over the price",
+			schedules: []models.PriceSchedule{priority(schedule(TargetProduct, KindFixedPrice, 9900), 2), priority(schedule(TargetProduct, KindFixedPrice, 5000), 1)},
+			want:      9900,
+			winner:    0,
+		},
+		{
+			name:      "the lowest price wins",
+			schedules: []models.PriceSchedule{schedule(TargetSeller, KindPercentOff, 1000), schedule(TargetSeller, KindAmountOff, 2500), schedule(TargetSeller, KindPercentOff, 2000)},
+			want:      7500,
+			winner:    1,
+		},
+		{
+			name:      "the latest start wins",
+			schedules: []models.PriceSchedule{schedule(TargetCategory, KindPercentOff, 1000), latest, schedule(TargetCategory, KindAmountOff, 1000)},
+			want:      9000,
+			winner:    1,
+		},
+		{
+			name:      "schedules do not stack",
+			schedules: []models.PriceSchedule{schedule(TargetProduct, KindAmountOff, 1000), schedule(TargetProduct, KindAmountOff, 1000)},
+			want:      9000,
+			winner:    0,
+		},
+	} {
+		resolved := Resolve(test.schedules, product, scheduleAt)
+		if resolved.Price.Amount != test.want || resolved.Sale == nil || resolved.Sale.ScheduleId != test.schedules[test.winner].ID {
+			t.Errorf("%s: price %v, sale %+v, want %d from schedule %d", test.name, resolved.Price, resolved.Sale, test.want, test.winner)
+		}
+	}
+}
+
+func TestResolvePrice(t *testing.T) {
+	other := product
+	other.ID, other.SellerId, other.Category = uuid.New(), uuid.New(), "music"
+	euros := schedule(TargetProduct, KindAmountOff, 100)
+	euros.Amount.Currency = "EUR"
+
+	for _, test := range []struct {
+		name     string
+		schedule models.PriceSchedule
+		product  models.Product
+		want     int64
+		sale     bool
+	}{
+		{"percent off", schedule(TargetProduct, KindPercentOff, 1500), product, 8500, true},
+		{"amount off", schedule(TargetSeller, KindAmountOff, 1), product, 9999, true},
+		{"fixed price", schedule(TargetProduct, KindFixedPrice, 4999), product, 4999, true},
+		// Prices never go below zero
+		{"amount off the whole price", schedule(TargetCategory, KindAmountOff, 20000), product, 0, true},
+		{"whole price off", schedule(TargetCategory, KindPercentOff, 10000), product, 0, true},
+		// Percentages round half up to the minor unit
+		{"half a cent off", schedule(TargetProduct, KindPercentOff, 500), models.Product{ID: product.ID, Price: money.Money{Currency: "USD", Amount: 10}}, 9, true},
+		{"a third off", schedule(TargetProduct, KindPercentOff, 3333), models.Product{ID: product.ID, Price: money.Money{Currency: "USD", Amount: 100}}, 67, true},
+		// A schedule which does not change the price is no sale
+		{"same fixed price", schedule(TargetProduct, KindFixedPrice, 10000), product, 10000, false},
+		{"other currency", euros, product, 10000, false},
+		{"other product", schedule(TargetProduct, KindPercentOff, 1000), other, 10000, false},
+		{"other seller", schedule(TargetSeller, KindPercentOff, 1000), other, 10000, false},
+		{"other category", schedule(TargetCategory, KindPercentOff, 1000), other, 10000, false},
+	} {
+		resolved := Resolve([]models.PriceSchedule{test.schedule}, test.product, scheduleAt)
+		if resolved.Price.Amount != test.want || (resolved.Sale != nil) != test.sale {
+			t.Errorf("%s: price %v, sale %+v, want %d", test.name, resolved.Price, resolved.Sale, test.want)
+		}
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	valid := []models.PriceSchedule{
+		schedule(TargetCategory, KindPercentOff, 10000),
+		schedule(TargetSeller, KindAmountOff, 1),
+		schedule(TargetProduct, KindFixedPrice, 0),
+	}
+	for _, sale := range valid {
+		if err := ValidateSchedule(sale); err != nil {
+			t.Errorf("ValidateSchedule(%s %s): %v", sale.TargetType, sale.Kind, err)
+		}
+	}
+
+	invalid := map[string]func(*models.PriceSchedule){
+		"no name":             func(s *models.PriceSchedule) { s.Name = "" },
+		"unknown target":      func(s *models.PriceSchedule) { s.TargetType = "brand" },
+		"no target":           func(s *models.PriceSchedule) { s.TargetId = "" },
+		"unknown kind":        func(s *models.PriceSchedule) { s.Kind = "bogo" },
+		"nothing off":         func(s *models.PriceSchedule) { s.PercentOff = 0 },
+		"more than all off":   func(s *models.PriceSchedule) { s.PercentOff = 10001 },
+		"no end":              func(s *models.PriceSchedule) { s.EndAt = time.Time{} },
+		"ending at the start": func(s *models.PriceSchedule) { s.EndAt = s.StartAt },
+	}
+	for name, change := range invalid {
+		sale := schedule(TargetProduct, KindPercentOff, 1000)
+		change(&sale)
+		if err := ValidateSchedule(sale); err == nil {
+			t.Errorf("ValidateSchedule(%s) succeeded, want an error", name)
+		}
+	}
+	for name, sale := range map[string]models.PriceSchedule{
+		"no amount off":        schedule(TargetProduct, KindAmountOff, 0),
+		"negative fixed price": schedule(TargetProduct, KindFixedPrice, -1),
+		"fixed category price": schedule(TargetCategory, KindFixedPrice, 100),
+		"unknown currency": func() models.PriceSchedule {
+			s := schedule(TargetProduct, KindAmountOff, 100)
+			s.Amount.Currency = "XXX"
+			return s
+		}(),
+	} {
+		if err := ValidateSchedule(sale); err == nil {
+			t.Errorf("ValidateSchedule(%s) succeeded, want an error", name)
+		}
+	}
+}
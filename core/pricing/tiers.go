@@ -0,0 +1,71 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// ValidateTiers checks the tiers of a customer group of a product. Tiers must
+// be in the currency of the product, ordered by strictly increasing minimum
+// quantity, and must not raise the unit price as the quantity grows.
+func ValidateTiers(tiers []models.PriceTier, currency string) error {
+	for i, tier := range tiers {
+		if tier.MinQuantity < 1 {
+			return fmt.Errorf("tier %d: minimum quantity must be at least 1", i)
+		}
+		if tier.UnitPrice.Currency != currency {
+			return fmt.Errorf("tier %d: currency %s differs from the product currency %s", i, tier.UnitPrice.Currency, currency)
+		}
+		if tier.UnitPrice.Amount < 0 {
+			return fmt.Errorf("tier %d: unit price must not be negative", i)
+		}
+		if i == 0 {
+			continue
+		}
+		if tier.MinQuantity <= tiers[i-1].MinQuantity {
+			return fmt.Errorf("tier %d: minimum quantities must increase", i)
+		}
+		if tier.UnitPrice.Amount > tiers[i-1].UnitPrice.Amount {
+			return fmt.Errorf("tier %d: unit price must not exceed the price of smaller quantities", i)
+		}
+	}
+	return nil
+}
+
+// Quote is the price of a quantity of a product.
+type Quote struct {
+	// ListPrice is the unit price without tiers, including any sale.
+	ListPrice money.Money
+	UnitPrice money.Money
+	Extended  money.Money
+	// Tier is the tier applied, nil when the list price was cheaper or no
+	// tier covers the quantity.
+	Tier *models.PriceTier
+}
+
+// QuoteProduct prices quantity units of a product with its loaded tiers. The
+// buyer pays the lower of the tier price and the effective list price, so a
+// running sale is never undercut by a tier.
+func QuoteProduct(product models.Product, quantity int32) (Quote, error) {
+	quote := Quote{ListPrice: product.Price, UnitPrice: product.Price}
+	for i := range product.PriceTiers {
+		tier := &product.PriceTiers[i]
+		if tier.MinQuantity > quantity {
+			break
+		}
+		if tier.UnitPrice.Amount < product.Price.Amount {
+			quote.UnitPrice, quote.Tier = tier.UnitPrice, tier
+		} else {
+			quote.UnitPrice, quote.Tier = product.Price, nil
+		}
+	}
+
+	if quote.UnitPrice.Amount > 0 && int64(quantity) > math.MaxInt64/quote.UnitPrice.Amount {
+		return Quote{}, money.ErrOutOfRange
+	}
+	quote.Extended = money.Money{Currency: quote.UnitPrice.Currency, Amount: quote.UnitPrice.Amount * int64(quantity)}
+	return quote, nil
+}
@@ -0,0 +1,85 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+func tier(minQuantity int32, unitPrice int64) models.PriceTier {
+	return models.PriceTier{MinQuantity: minQuantity, UnitPrice: money.Money{Currency: "USD", Amount: unitPrice}}
+}
+
+func TestQuoteProduct(t *testing.T) {
+	tiered := models.Product{
+		Price:      money.Money{Currency: "USD", Amount: 1000},
+		PriceTiers: []models.PriceTier{tier(10, 900), tier(50, 800), tier(100, 800)},
+	}
+	// A running sale cheaper than the first tier
+	onSale := tiered
+	onSale.Price.Amount = 850
+
+	for _, test := range []struct {
+		name      string
+		product   models.Product
+		quantity  int32
+		unitPrice int64
+		tier      int32
+	}{
+		{"single unit", tiered, 1, 1000, 0},
+		{"below the first tier", tiered, 9, 1000, 0},
+		{"at the first tier", tiered, 10, 900, 10},
+		{"below the second tier", tiered, 49, 900, 10},
+		{"at the second tier", tiered, 50, 800, 50},
+		{"at a tier of the same price", tiered, 100, 800, 100},
+		{"beyond the last tier", tiered, 100000, 800, 100},
+		{"no units", tiered, 0, 1000, 0},
+		{"sale cheaper than a tier", onSale, 10, 850, 0},
+		{"tier cheaper than a sale", onSale, 50, 800, 50},
+		{"no tiers", models.Product{Price: money.Money{Currency: "USD", Amount: 1000}}, 50, 1000, 0},
+	} {
+		quote, err := QuoteProduct(test.product, test.quantity)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if quote.UnitPrice.Amount != test.unitPrice || quote.Extended.Amount != test.unitPrice*int64(test.quantity) || quote.ListPrice != test.product.Price {
+			t.Errorf("%s: quote %+v, want a unit price of %d", test.name, quote, test.unitPrice)
+		}
+		if (quote.Tier == nil) != (test.tier == 0) || (quote.Tier != nil && quote.Tier.MinQuantity != test.tier) {
+			t.Errorf("%s: tier %+v, want the tier from %d units", test.name, quote.Tier, test.tier)
+		}
+	}
+
+	expensive := models.Product{Price: money.Money{Currency: "USD", Amount: math.MaxInt64 / 1000}}
+	if _, err := QuoteProduct(expensive, 1000); err != nil {
+		t.Errorf("QuoteProduct at the limit: %v", err)
+	}
+	if quote, err := QuoteProduct(expensive, 1001); !errors.Is(err, money.ErrOutOfRange) {
+		t.Errorf("QuoteProduct beyond the limit = %+v, %v, want ErrOutOfRange", quote, err)
+	}
+}
+
+func TestValidateTiers(t *testing.T) {
+	if err := ValidateTiers([]models.PriceTier{tier(1, 1000), tier(10, 900), tier(50, 900)}, "USD"); err != nil {
+		t.Errorf("ValidateTiers: %v", err)
+	}
+	if err := ValidateTiers(nil, "USD"); err != nil {
+		t.Errorf("ValidateTiers(nil): %v", err)
+	}
+	for name, tiers := range map[string][]models.PriceTier{
+		"no units":            {tier(0, 1000)},
+		"other currency":      {{MinQuantity: 1, UnitPrice: money.Money{Currency: "EUR", Amount: 1000}}},
+		"negative price":      {tier(1, -1)},
+		"repeated quantity":   {tier(10, 900), tier(10, 800)},
+		"decreasing quantity": {tier(10, 900), tier(5, 800)},
+		"increasing price":    {tier(10, 900), tier(50, 901)},
+	} {
+		if err := ValidateTiers(tiers, "USD"); err == nil {
+			t.Errorf("ValidateTiers(%s) succeeded, want an error", name)
+		}
+	}
+}
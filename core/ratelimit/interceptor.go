@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterMetadataKey tells rejected callers how many seconds to wait.
+const RetryAfterMetadataKey = "retry-after"
+
+type Options struct {
+	// Default applies to every method without an entry in Methods.
+	Default Limit
+	// Methods overrides the limit per full method name,
+	// e.g. /ecommerce.ProductService/UpdateProduct.
+	Methods map[string]Limit
+	// SellerAgents are the clients whose requests are limited per seller,
+	// see security.Subject.
+	SellerAgents security.Role
+}
+
+func (o Options) limitFor(method string) Limit {
+	if limit, ok := o.Methods[method]; ok {
+		return limit
+	}
+	return o.Default
+}
+
+// UnaryServerInterceptor rejects calls with ResourceExhausted once the caller
+// used up its token bucket for the method. Buckets are keyed by the caller
+// (see security.Subject) and the method. Backend failures let requests through.
+func UnaryServerInterceptor(backend Backend, opts Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limit := opts.limitFor(info.FullMethod)
+		if limit.Rate <= 0 {
+			return handler(ctx, req)
+		}
+
+		subject := security.Subject(ctx, req, opts.SellerAgents)
+		decision, err := backend.Allow(ctx, info.FullMethod+"|"+subject, limit)
+		if err != nil {
+			logging.FromContext(ctx).Error("rate limit backend failed, allowing request", "error", err)
+			return handler(ctx, req)
+		}
+		if !decision.Allowed {
+			seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadataKey, strconv.Itoa(seconds)))
+			logging.FromContext(ctx).Warn("rate limit exceeded", "subject", subject, "retry_after_seconds", seconds)
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s, retry after %ds", info.FullMethod, seconds)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor limits the streaming RPCs a caller starts like
+// UnaryServerInterceptor, before any message is received. The seller of the
+// request is not known yet, so callers are identified by their mTLS client
+// identity or remote address.
+func StreamServerInterceptor(backend Backend, opts Options) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		limit := opts.limitFor(info.FullMethod)
+		if limit.Rate <= 0 {
+			return handler(srv, stream)
+		}
+
+		ctx := stream.Context()
+		subject := security.Subject(ctx, nil, opts.SellerAgents)
+		decision, err := backend.Allow(ctx, info.FullMethod+"|"+subject, limit)
+		if err != nil {
+			logging.FromContext(ctx).Error("rate limit backend failed, allowing request", "error", err)
+			return handler(srv, stream)
+		}
+		if !decision.Allowed {
+			seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadataKey, strconv.Itoa(seconds)))
+			logging.FromContext(ctx).Warn("rate limit exceeded", "subject", subject, "retry_after_seconds", seconds)
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s, retry after %ds", info.FullMethod, seconds)
+		}
+		return handler(srv, stream)
+	}
+}
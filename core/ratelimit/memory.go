@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryBackend keeps the token buckets in process, which enforces the limits
+// per replica. Buckets left untouched for idleTimeout are evicted.
+type MemoryBackend struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewMemoryBackend(idleTimeout time.Duration) *MemoryBackend {
+	b := &MemoryBackend{
+		buckets:     map[string]*bucket{},
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go b.sweep()
+	return b
+}
+
+func (b *MemoryBackend) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	if limit.Rate <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+	burst := math.Max(float64(limit.Burst), 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	bk, ok := b.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: burst, last: now}
+		b.buckets[key] = bk
+	}
+
+	elapsed := now.Sub(bk.last).Seconds()
+	bk.tokens = math.Min(burst, bk.tokens+elapsed*limit.Rate)
+	bk.last = now
+
+	if bk.tokens >= 1 {
+		bk.tokens--
+		return Decision{Allowed: true}, nil
+	}
+	wait := (1 - bk.tokens) / limit.Rate
+	return Decision{Allowed: false, RetryAfter: time.Duration(wait * float64(time.Second))}, nil
+}
+
+// Close stops the eviction of idle buckets.
+func (b *MemoryBackend) Close(ctx context.Context) error {
+	close(b.stop)
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *MemoryBackend) sweep() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.idleTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.evictIdle()
+		}
+	}
+}
+
+func (b *MemoryBackend) evictIdle() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cutoff := b.now().Add(-b.idleTimeout)
+	for key, bk := range b.buckets {
+		if bk.last.Before(cutoff) {
+			delete(b.buckets, key)
+		}
+	}
+}
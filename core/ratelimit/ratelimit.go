@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit describes a token bucket: Rate tokens are added per second up to a
+// maximum of Burst. A non positive Rate disables limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision is the outcome of taking a token from a bucket.
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long the caller should wait before the next token is
+	// available. It is only set when the request was denied.
+	RetryAfter time.Duration
+}
+
+// Backend stores the token buckets. Implementations backed by a shared store
+// enforce limits across every replica of the service.
+type Backend interface {
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+}
@@ -0,0 +1,76 @@
+package security
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type identityKey struct{}
+
+// ClientIdentity returns the service identity of the mTLS authenticated caller.
+func ClientIdentity(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey{}).(string)
+	return identity, ok
+}
+
+// UnaryServerInterceptor resolves the identity of the client certificate, if
+// any, and makes it available through ClientIdentity.
+func UnaryServerInterceptor(identities map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if cert := peerCertificate(ctx); cert != nil {
+			if identity, ok := identityFor(cert, identities); ok {
+				ctx = context.WithValue(ctx, identityKey{}, identity)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// identityFor maps the SANs of a certificate to a configured identity. When
+// no mapping is configured the common name is used as the identity.
+func identityFor(cert *x509.Certificate, identities map[string]string) (string, bool) {
+	if len(identities) == 0 {
+		return cert.Subject.CommonName, cert.Subject.CommonName != ""
+	}
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, san := range sans {
+		if identity, ok := identities[san]; ok {
+			return identity, true
+		}
+	}
+	return "", false
+}
+
+// StreamServerInterceptor is the UnaryServerInterceptor of streaming RPCs.
+func StreamServerInterceptor(identities map[string]string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		if cert := peerCertificate(ctx); cert != nil {
+			if identity, ok := identityFor(cert, identities); ok {
+				stream = grpcutil.WithContext(stream, context.WithValue(ctx, identityKey{}, identity))
+			}
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,37 @@
+package security
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Role grants access to privileged RPCs to a set of mTLS client identities.
+// The zero Role grants access to nobody.
+type Role struct {
+	name       string
+	identities map[string]bool
+	allowAll   bool
+}
+
+// NewRole returns a role held by the given identities, or by every caller
+// when allowAll is set.
+func NewRole(name string, identities []string, allowAll bool) Role {
+	role := Role{name: name, identities: make(map[string]bool, len(identities)), allowAll: allowAll}
+	for _, identity := range identities {
+		role.identities[identity] = true
+	}
+	return role
+}
+
+// Check fails with PERMISSION_DENIED unless the caller holds the role.
+func (r Role) Check(ctx context.Context) error {
+	if r.allowAll {
+		return nil
+	}
+	if identity, ok := ClientIdentity(ctx); ok && r.identities[identity] {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "requires the %s role", r.name)
+}
@@ -0,0 +1,44 @@
+package security
+
+import (
+	"context"
+	"net"
+
+	"github.com/tittuvarghese/ss-go-product-service/proto"
+	"google.golang.org/grpc/peer"
+)
+
+// SellerIdFromRequest finds the seller a request acts on behalf of, either on
+// the request itself or on the product it carries.
+func SellerIdFromRequest(req interface{}) string {
+	if r, ok := req.(interface{ GetSellerId() string }); ok && r.GetSellerId() != "" {
+		return r.GetSellerId()
+	}
+	if r, ok := req.(interface{ GetProduct() *proto.Product }); ok {
+		return r.GetProduct().GetSellerId()
+	}
+	return ""
+}
+
+// Subject identifies the caller of an RPC: the mTLS client identity when
+// authenticated, together with the seller the request is made for when the
+// client holds sellerAgents, so the sellers behind one client such as the
+// gateway are told apart. The seller of a request is chosen by the client, so
+// it is never trusted on its own: without an identity the remote address
+// identifies the caller.
+func Subject(ctx context.Context, req interface{}, sellerAgents Role) string {
+	if identity, ok := ClientIdentity(ctx); ok {
+		if sellerId := SellerIdFromRequest(req); sellerId != "" && sellerAgents.Check(ctx) == nil {
+			return "client:" + identity + "/seller:" + sellerId
+		}
+		return "client:" + identity
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+	return "anonymous"
+}
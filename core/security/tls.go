@@ -0,0 +1,188 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-core/logger"
+	"google.golang.org/grpc/credentials"
+)
+
+var log = logger.NewLogger("product-service")
+
+// Client certificate policies
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the CA bundle client certificates are verified against.
+	ClientCAFile string
+	// ClientAuth is one of the ClientAuth constants.
+	ClientAuth string
+	// Identities maps the SANs of trusted client certificates to the service
+	// identity they authenticate as. When set, clients presenting any other
+	// certificate are rejected during the handshake.
+	Identities map[string]string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// NewServerCredentials builds TLS credentials which pick up renewed
+// certificates and CA bundles from disk without restarting the server.
+func NewServerCredentials(opts TLSOptions) (credentials.TransportCredentials, error) {
+	reloader := &certReloader{opts: opts}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: reloader.configForClient,
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// certReloader serves the most recently loaded certificate material. Files
+// are checked at most once per ReloadInterval, during a handshake.
+type certReloader struct {
+	opts TLSOptions
+
+	mu        sync.RWMutex
+	config    *tls.Config
+	modTimes  []time.Time
+	lastCheck time.Time
+}
+
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+func (r *certReloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= r.opts.ReloadInterval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	previous := r.modTimes
+	r.mu.Unlock()
+
+	current, err := modTimes(r.files())
+	if err != nil {
+		log.Error("Unable to check TLS files for changes", err)
+		return
+	}
+	if equalTimes(previous, current) {
+		return
+	}
+	if err := r.load(); err != nil {
+		// Keep serving the previous certificate until the files are fixed
+		log.Error("Unable to reload TLS certificate, keeping the previous one", err)
+		return
+	}
+	log.Info("Reloaded TLS certificate from " + r.opts.CertFile)
+}
+
+func (r *certReloader) load() error {
+	stamps, err := modTimes(r.files())
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	switch r.opts.ClientAuth {
+	case "", ClientAuthNone:
+		config.ClientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return fmt.Errorf("unknown client auth policy %q", r.opts.ClientAuth)
+	}
+
+	if r.opts.ClientCAFile != "" {
+		bundle, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("client CA bundle %s contains no certificates", r.opts.ClientCAFile)
+		}
+		config.ClientCAs = pool
+	}
+
+	if len(r.opts.Identities) > 0 {
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return nil
+			}
+			if _, ok := identityFor(state.PeerCertificates[0], r.opts.Identities); !ok {
+				return fmt.Errorf("client certificate %q is not allowed", state.PeerCertificates[0].Subject.CommonName)
+			}
+			return nil
+		}
+	}
+
+	r.mu.Lock()
+	r.config = config
+	r.modTimes = stamps
+	r.mu.Unlock()
+	return nil
+}
+
+func modTimes(files []string) ([]time.Time, error) {
+	stamps := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, info.ModTime())
+	}
+	return stamps, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package shipping
+
+import (
+	"math"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// Item is a quantity of a product to ship. The shipping base price of the
+// product must be in the currency of the zone.
+type Item struct {
+	Product  models.Product
+	Quantity int32
+}
+
+// ItemQuote is the cost of shipping one item on its own. Weights are in
+// kilograms and cover the whole quantity.
+type ItemQuote struct {
+	Item
+	ActualWeight     float64
+	VolumetricWeight float64
+	BillableWeight   float64
+	// BasePrice is the shipping base price of the product times the quantity.
+	BasePrice money.Money
+	// Rate is the zone rate for the billable weight.
+	Rate  money.Money
+	Total money.Money
+}
+
+// Quote is the cost of shipping items, each on its own and together as one
+// consolidated shipment, which pays the zone rate once for the summed
+// billable weight.
+type Quote struct {
+	Items          []ItemQuote
+	BillableWeight float64
+	BasePrice      money.Money
+	Rate           money.Money
+	Total          money.Money
+}
+
+// VolumetricWeight returns the weight in kilograms a carrier bills for a
+// parcel of the given size in centimetres.
+func (r Rates) VolumetricWeight(width, height, depth float64) float64 {
+	return width * height * depth / r.VolumetricDivisor
+}
+
+// Quote prices the shipment of items to zone. The billable weight of an item
+// is the larger of its actual and volumetric weight.
+func (r Rates) Quote(zone Zone, items []Item) (Quote, error) {
+	quote := Quote{
+		BasePrice: money.Money{Currency: zone.Currency},
+	}
+	for _, item := range items {
+		quantity := float64(item.Quantity)
+		product := item.Product
+		line := ItemQuote{
+			Item:             item,
+			ActualWeight:     product.Weight * quantity,
+			VolumetricWeight: r.VolumetricWeight(product.Width, product.Height, product.Depth) * quantity,
+		}
+		line.BillableWeight = math.Max(line.ActualWeight, line.VolumetricWeight)
+
+		base, err := multiply(product.ShippingBasePrice, int64(item.Quantity))
+		if err != nil {
+			return Quote{}, err
+		}
+		line.BasePrice = base
+		if line.Rate, err = zone.Charge(line.BillableWeight); err != nil {
+			return Quote{}, err
+		}
+		if line.Total, err = add(line.BasePrice, line.Rate); err != nil {
+			return Quote{}, err
+		}
+		if quote.BasePrice, err = add(quote.BasePrice, line.BasePrice); err != nil {
+			return Quote{}, err
+		}
+		quote.BillableWeight += line.BillableWeight
+		quote.Items = append(quote.Items, line)
+	}
+
+	var err error
+	if quote.Rate, err = zone.Charge(quote.BillableWeight); err != nil {
+		return Quote{}, err
+	}
+	if quote.Total, err = add(quote.BasePrice, quote.Rate); err != nil {
+		return Quote{}, err
+	}
+	return quote, nil
+}
+
+func multiply(amount money.Money, factor int64) (money.Money, error) {
+	if amount.Amount > 0 && factor > math.MaxInt64/amount.Amount {
+		return money.Money{}, money.ErrOutOfRange
+	}
+	return money.Money{Currency: amount.Currency, Amount: amount.Amount * factor}, nil
+}
+
+func add(a, b money.Money) (money.Money, error) {
+	if b.Amount > 0 && a.Amount > math.MaxInt64-b.Amount {
+		return money.Money{}, money.ErrOutOfRange
+	}
+	return money.Money{Currency: a.Currency, Amount: a.Amount + b.Amount}, nil
+}
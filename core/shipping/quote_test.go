@@ -0,0 +1,64 @@
+package shipping
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+func TestQuote(t *testing.T) {
+	rates := Rates{VolumetricDivisor: DefaultVolumetricDivisor}
+	// Billed on its actual weight
+	heavy := models.Product{Weight: 1, Width: 10, Height: 10, Depth: 10, ShippingBasePrice: money.Money{Currency: "USD", Amount: 100}}
+	// Billed on its volumetric weight of 4.8 kg
+	bulky := models.Product{Weight: 0.1, Width: 40, Height: 30, Depth: 20, ShippingBasePrice: money.Money{Currency: "USD", Amount: 50}}
+
+	quote, err := rates.Quote(domestic, []Item{{Product: heavy, Quantity: 2}, {Product: bulky, Quantity: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct {
+		actual, volumetric, billable float64
+		base, rate, total            int64
+	}{
+		{2, 0.4, 2, 200, 900, 1100},
+		{0.1, 4.8, 4.8, 50, 1500, 1550},
+	} {
+		line := quote.Items[i]
+		if line.ActualWeight != want.actual || line.VolumetricWeight != want.volumetric || line.BillableWeight != want.billable {
+			t.Errorf("item %d weighs %v actual, %v volumetric, %v billable, want %+v", i, line.ActualWeight, line.VolumetricWeight, line.BillableWeight, want)
+		}
+		if line.BasePrice.Amount != want.base || line.Rate.Amount != want.rate || line.Total.Amount != want.total {
+			t.Errorf("item %d costs %v + %v = %v, want %+v", i, line.BasePrice, line.Rate, line.Total, want)
+		}
+	}
+	// The consolidated shipment pays the rate of 6.8 kg once
+	if quote.BillableWeight != 6.8 || quote.BasePrice.Amount != 250 || quote.Rate.Amount != 1500 || quote.Total.Amount != 1750 || quote.Total.Currency != "USD" {
+		t.Errorf("shipment of %v kg costs %v + %v = %v, want 250 + 1500 = 1750", quote.BillableWeight, quote.BasePrice, quote.Rate, quote.Total)
+	}
+}
+
+func TestQuoteErrors(t *testing.T) {
+	rates := Rates{VolumetricDivisor: DefaultVolumetricDivisor}
+	capped := Zone{Currency: "USD", Rates: []Rate{{MaxWeight: 5, Amount: 300}}}
+	parcel := models.Product{Weight: 3, ShippingBasePrice: money.Money{Currency: "USD", Amount: 100}}
+
+	// Each item fits the zone but the shipment does not
+	if _, err := rates.Quote(capped, []Item{{Product: parcel, Quantity: 1}, {Product: parcel, Quantity: 1}}); !errors.Is(err, ErrOverweight) {
+		t.Errorf("Quote of 6 kg = %v, want ErrOverweight", err)
+	}
+	if _, err := rates.Quote(capped, []Item{{Product: parcel, Quantity: 2}}); !errors.Is(err, ErrOverweight) {
+		t.Errorf("Quote of 2 items of 3 kg = %v, want ErrOverweight", err)
+	}
+
+	expensive := models.Product{Weight: 1, ShippingBasePrice: money.Money{Currency: "USD", Amount: math.MaxInt64 / 2}}
+	if _, err := rates.Quote(domestic, []Item{{Product: expensive, Quantity: 3}}); !errors.Is(err, money.ErrOutOfRange) {
+		t.Errorf("Quote of an overflowing base price = %v, want ErrOutOfRange", err)
+	}
+	if _, err := rates.Quote(domestic, []Item{{Product: expensive, Quantity: 1}, {Product: expensive, Quantity: 1}, {Product: expensive, Quantity: 1}}); !errors.Is(err, money.ErrOutOfRange) {
+		t.Errorf("Quote of overflowing base prices = %v, want ErrOutOfRange", err)
+	}
+}
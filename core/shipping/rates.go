@@ -0,0 +1,95 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+var (
+	ErrUnknownZone = errors.New("unknown shipping zone")
+	ErrOverweight  = errors.New("shipment exceeds the heaviest rate of the zone")
+)
+
+// DefaultVolumetricDivisor turns a volume in cubic centimetres into a
+// volumetric weight in kilograms, as used by most parcel carriers.
+const DefaultVolumetricDivisor = 5000
+
+// Rate is the charge for shipments of up to MaxWeight kilograms, in minor
+// units of the currency of its zone.
+type Rate struct {
+	MaxWeight float64
+	Amount    int64
+}
+
+// Zone is a destination zone with its rate table. Rates are ordered by
+// increasing MaxWeight; shipments heavier than the last rate are charged
+// ExtraKgAmount for every started kilogram above it, or rejected when it is
+// zero.
+type Zone struct {
+	Currency      string
+	Rates         []Rate
+	ExtraKgAmount int64
+}
+
+// Validate reports whether the rate table of the zone can be applied.
+func (z Zone) Validate() error {
+	if _, err := money.MinorUnits(z.Currency); err != nil {
+		return err
+	}
+	if len(z.Rates) == 0 {
+		return fmt.Errorf("at least one rate is required")
+	}
+	for i, rate := range z.Rates {
+		if rate.MaxWeight <= 0 {
+			return fmt.Errorf("rates[%d]: max weight must be positive", i)
+		}
+		if rate.Amount < 0 {
+			return fmt.Errorf("rates[%d]: amount must not be negative", i)
+		}
+		if i > 0 && rate.MaxWeight <= z.Rates[i-1].MaxWeight {
+			return fmt.Errorf("rates[%d]: max weights must increase", i)
+		}
+	}
+	if z.ExtraKgAmount < 0 {
+		return fmt.Errorf("extra kg amount must not be negative")
+	}
+	return nil
+}
+
+// Charge returns the rate of the zone for a shipment of weight kilograms.
+func (z Zone) Charge(weight float64) (money.Money, error) {
+	for _, rate := range z.Rates {
+		if weight <= rate.MaxWeight {
+			return money.Money{Currency: z.Currency, Amount: rate.Amount}, nil
+		}
+	}
+
+	last := z.Rates[len(z.Rates)-1]
+	if z.ExtraKgAmount == 0 {
+		return money.Money{}, fmt.Errorf("%w: %.2f kg above %.2f kg", ErrOverweight, weight, last.MaxWeight)
+	}
+	extra := math.Ceil(weight - last.MaxWeight)
+	if extra > float64((math.MaxInt64-last.Amount)/z.ExtraKgAmount) {
+		return money.Money{}, money.ErrOutOfRange
+	}
+	return money.Money{Currency: z.Currency, Amount: last.Amount + int64(extra)*z.ExtraKgAmount}, nil
+}
+
+// Rates holds the zones shipments are quoted for.
+type Rates struct {
+	// VolumetricDivisor turns cubic centimetres into kilograms.
+	VolumetricDivisor float64
+	Zones             map[string]Zone
+}
+
+// Zone returns the zone of the given name.
+func (r Rates) Zone(name string) (Zone, error) {
+	zone, ok := r.Zones[name]
+	if !ok {
+		return Zone{}, fmt.Errorf("%w %q", ErrUnknownZone, name)
+	}
+	return zone, nil
+}
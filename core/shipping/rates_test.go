@@ -0,0 +1,85 @@
+package shipping
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+)
+
+var domestic = Zone{
+	Currency:      "USD",
+	Rates:         []Rate{{MaxWeight: 0.5, Amount: 500}, {MaxWeight: 2, Amount: 900}, {MaxWeight: 10, Amount: 1500}},
+	ExtraKgAmount: 200,
+}
+
+func TestZoneCharge(t *testing.T) {
+	// Free up to a kilogram, and no shipments above 5 kg
+	local := Zone{Currency: "USD", Rates: []Rate{{MaxWeight: 1, Amount: 0}, {MaxWeight: 5, Amount: 300}}}
+
+	for _, test := range []struct {
+		zone   Zone
+		weight float64
+		want   int64
+		err    error
+	}{
+		{domestic, 0, 500, nil},
+		// Brackets include their max weight
+		{domestic, 0.5, 500, nil},
+		{domestic, 0.5001, 900, nil},
+		{domestic, 2, 900, nil},
+		{domestic, 10, 1500, nil},
+		// Every started kilogram above the last bracket is charged
+		{domestic, 10.2, 1700, nil},
+		{domestic, 11, 1700, nil},
+		{domestic, 11.01, 1900, nil},
+		{local, 0.8, 0, nil},
+		{local, 1.2, 300, nil},
+		{local, 5.01, 0, ErrOverweight},
+		{Zone{Currency: "USD", Rates: []Rate{{MaxWeight: 1, Amount: 100}}, ExtraKgAmount: math.MaxInt64 / 2}, 4, 0, money.ErrOutOfRange},
+	} {
+		charge, err := test.zone.Charge(test.weight)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("Charge(%v) = %v, %v, want %v", test.weight, charge, err, test.err)
+			}
+			continue
+		}
+		if err != nil || charge != (money.Money{Currency: "USD", Amount: test.want}) {
+			t.Errorf("Charge(%v) = %v, %v, want %d", test.weight, charge, err, test.want)
+		}
+	}
+}
+
+func TestZoneValidate(t *testing.T) {
+	if err := domestic.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for name, zone := range map[string]Zone{
+		"unknown currency":   {Currency: "XXX", Rates: domestic.Rates},
+		"no rates":           {Currency: "USD"},
+		"no weight":          {Currency: "USD", Rates: []Rate{{MaxWeight: 0, Amount: 100}}},
+		"negative amount":    {Currency: "USD", Rates: []Rate{{MaxWeight: 1, Amount: -1}}},
+		"repeated weight":    {Currency: "USD", Rates: []Rate{{MaxWeight: 1, Amount: 100}, {MaxWeight: 1, Amount: 200}}},
+		"negative extra kg":  {Currency: "USD", Rates: domestic.Rates, ExtraKgAmount: -1},
+		"decreasing weights": {Currency: "USD", Rates: []Rate{{MaxWeight: 2, Amount: 100}, {MaxWeight: 1, Amount: 200}}},
+	} {
+		if err := zone.Validate(); err == nil {
+			t.Errorf("Validate(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestRatesZone(t *testing.T) {
+	rates := Rates{Zones: map[string]Zone{"domestic": domestic}}
+	if zone, err := rates.Zone("domestic"); err != nil || zone.Currency != "USD" {
+		t.Errorf("Zone(domestic) = %+v, %v", zone, err)
+	}
+	// Zone names are matched exactly
+	for _, name := range []string{"", "Domestic", "international"} {
+		if _, err := rates.Zone(name); !errors.Is(err, ErrUnknownZone) {
+			t.Errorf("Zone(%q) = %v, want ErrUnknownZone", name, err)
+		}
+	}
+}
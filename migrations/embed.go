@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations of the product
+// service, one directory per database dialect. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, and every dialect
+// must provide the same versions.
+package migrations
+
+import "embed"
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var Files embed.FS
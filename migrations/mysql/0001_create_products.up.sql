@@ -0,0 +1,17 @@
+-- Baseline schema, matching the table previously created by AutoMigrate
+CREATE TABLE IF NOT EXISTS products (
+    id                      UUID           NOT NULL,
+    name                    VARCHAR(255)   NOT NULL,
+    quantity                INT            NOT NULL,
+    type                    VARCHAR(20)    NOT NULL,
+    category                VARCHAR(100)   NOT NULL,
+    image_urls              JSON,
+    price                   DECIMAL(10, 2) NOT NULL,
+    width                   DECIMAL(5, 2),
+    height                  DECIMAL(5, 2),
+    weight                  DECIMAL(5, 2),
+    shipping_base_price     DECIMAL(10, 2) NOT NULL,
+    base_delivery_timelines INT            NOT NULL,
+    seller_id               UUID           NOT NULL,
+    PRIMARY KEY (id)
+);
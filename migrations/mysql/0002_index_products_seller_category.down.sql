@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_category ON products;
+DROP INDEX IF EXISTS idx_products_seller_id ON products;
@@ -0,0 +1,14 @@
+-- Append only history of every product mutation
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id         UUID         NOT NULL,
+    product_id UUID         NOT NULL,
+    revision   BIGINT       NOT NULL,
+    actor      VARCHAR(255) NOT NULL,
+    source     VARCHAR(100) NOT NULL,
+    created_at DATETIME(6)  NOT NULL,
+    snapshot   JSON         NOT NULL,
+    changes    JSON         NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_product_revisions_product_revision (product_id, revision),
+    KEY idx_product_revisions_product_created_at (product_id, created_at)
+);
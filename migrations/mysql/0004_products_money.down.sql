@@ -0,0 +1,20 @@
+-- Currencies are lost, amounts are converted back assuming two decimals
+ALTER TABLE products
+    ADD COLUMN price               DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN shipping_base_price DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price               = price_amount / 100,
+    shipping_base_price = shipping_base_price_amount / 100;
+
+ALTER TABLE products
+    DROP COLUMN price_currency,
+    DROP COLUMN price_amount,
+    DROP COLUMN shipping_base_price_currency,
+    DROP COLUMN shipping_base_price_amount;
+
+UPDATE product_revisions
+SET snapshot = JSON_SET(snapshot,
+    '$.price', JSON_EXTRACT(snapshot, '$.price.amount') / 100,
+    '$.shipping_base_price', JSON_EXTRACT(snapshot, '$.shipping_base_price.amount') / 100)
+WHERE JSON_TYPE(JSON_EXTRACT(snapshot, '$.price')) = 'OBJECT';
@@ -0,0 +1,24 @@
+-- Prices move from decimals to integer minor units with a currency. Existing
+-- prices had no currency and are assumed to be USD; update price_currency and
+-- shipping_base_price_currency after migrating when the catalogue used another
+-- currency with two decimals.
+ALTER TABLE products
+    ADD COLUMN price_currency               CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN price_amount                 BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN shipping_base_price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN shipping_base_price_amount   BIGINT  NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price_amount               = ROUND(price * 100),
+    shipping_base_price_amount = ROUND(shipping_base_price * 100);
+
+ALTER TABLE products
+    DROP COLUMN price,
+    DROP COLUMN shipping_base_price;
+
+-- Keep the recorded snapshots readable by converting their prices as well
+UPDATE product_revisions
+SET snapshot = JSON_SET(snapshot,
+    '$.price', JSON_OBJECT('currency', 'USD', 'amount', CAST(ROUND(JSON_EXTRACT(snapshot, '$.price') * 100) AS SIGNED)),
+    '$.shipping_base_price', JSON_OBJECT('currency', 'USD', 'amount', CAST(ROUND(JSON_EXTRACT(snapshot, '$.shipping_base_price') * 100) AS SIGNED)))
+WHERE JSON_TYPE(JSON_EXTRACT(snapshot, '$.price')) IN ('DOUBLE', 'INTEGER', 'DECIMAL');
@@ -0,0 +1,16 @@
+-- Explicit prices of a product in other currencies than its own
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id UUID    NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    amount     BIGINT  NOT NULL,
+    PRIMARY KEY (product_id, currency)
+);
+
+-- Exchange rates used to convert prices without an explicit override
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency  CHAR(3)     NOT NULL,
+    quote_currency CHAR(3)     NOT NULL,
+    rate           VARCHAR(32) NOT NULL,
+    updated_at     DATETIME(6) NOT NULL,
+    PRIMARY KEY (base_currency, quote_currency)
+);
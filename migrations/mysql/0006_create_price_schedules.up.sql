@@ -0,0 +1,18 @@
+-- Time boxed price changes, applied when products are read
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id              UUID         NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    target_type     VARCHAR(20)  NOT NULL,
+    target_id       VARCHAR(100) NOT NULL,
+    kind            VARCHAR(20)  NOT NULL,
+    percent_off     BIGINT       NOT NULL,
+    amount_currency CHAR(3)      NOT NULL,
+    amount_amount   BIGINT       NOT NULL,
+    start_at        DATETIME(6)  NOT NULL,
+    end_at          DATETIME(6)  NOT NULL,
+    priority        INT          NOT NULL,
+    created_by      VARCHAR(255) NOT NULL,
+    created_at      DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_price_schedules_end_at (end_at)
+);
@@ -0,0 +1,9 @@
+-- Volume pricing, per product and customer group
+CREATE TABLE IF NOT EXISTS price_tiers (
+    product_id          UUID        NOT NULL,
+    customer_group      VARCHAR(50) NOT NULL,
+    min_quantity        INT         NOT NULL,
+    unit_price_currency CHAR(3)     NOT NULL,
+    unit_price_amount   BIGINT      NOT NULL,
+    PRIMARY KEY (product_id, customer_group, min_quantity)
+);
@@ -0,0 +1,3 @@
+-- Depth completes the size of a product for volumetric shipping weights,
+-- existing products have no depth until their seller sets one
+ALTER TABLE products ADD COLUMN depth DECIMAL(5, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_status ON products;
+ALTER TABLE products DROP COLUMN status_reason;
+ALTER TABLE products DROP COLUMN status;
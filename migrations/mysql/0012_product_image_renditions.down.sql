@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_image_renditions;
+DROP INDEX IF EXISTS idx_product_images_renditions_status ON product_images;
+ALTER TABLE product_images DROP COLUMN renditions_status;
+ALTER TABLE product_images DROP COLUMN orientation;
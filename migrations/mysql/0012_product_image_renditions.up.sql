@@ -0,0 +1,16 @@
+-- Images uploaded before renditions existed are rendered by the sweeper
+ALTER TABLE product_images ADD COLUMN orientation INT NOT NULL DEFAULT 1;
+ALTER TABLE product_images ADD COLUMN renditions_status VARCHAR(20) NOT NULL DEFAULT 'pending';
+
+-- Resized copies of product images
+CREATE TABLE IF NOT EXISTS product_image_renditions (
+    image_id     UUID         NOT NULL,
+    name         VARCHAR(20)  NOT NULL,
+    content_type VARCHAR(50)  NOT NULL,
+    size         BIGINT       NOT NULL,
+    width        INT          NOT NULL,
+    height       INT          NOT NULL,
+    storage_key  VARCHAR(255) NOT NULL,
+    PRIMARY KEY (image_id, name)
+);
+CREATE INDEX IF NOT EXISTS idx_product_images_renditions_status ON product_images (renditions_status);
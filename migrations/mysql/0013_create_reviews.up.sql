@@ -0,0 +1,32 @@
+-- Customer reviews of products, at most one per customer and product
+CREATE TABLE IF NOT EXISTS reviews (
+    id                UUID          NOT NULL,
+    product_id        UUID          NOT NULL,
+    customer_id       UUID          NOT NULL,
+    author_name       VARCHAR(100)  NOT NULL,
+    rating            INT           NOT NULL,
+    title             VARCHAR(200)  NOT NULL,
+    body              TEXT          NOT NULL,
+    verified_purchase BOOLEAN       NOT NULL,
+    status            VARCHAR(20)   NOT NULL,
+    status_reason     VARCHAR(500)  NOT NULL,
+    created_at        DATETIME(6)   NOT NULL,
+    updated_at        DATETIME(6)   NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_reviews_product_customer (product_id, customer_id),
+    KEY idx_reviews_product_status_created_at (product_id, status, created_at)
+);
+
+-- Ratings of the published reviews, kept per product for listings
+CREATE TABLE IF NOT EXISTS product_ratings (
+    product_id     UUID          NOT NULL,
+    review_count   INT           NOT NULL,
+    average_rating DECIMAL(3, 2) NOT NULL,
+    one_star       INT           NOT NULL,
+    two_stars      INT           NOT NULL,
+    three_stars    INT           NOT NULL,
+    four_stars     INT           NOT NULL,
+    five_stars     INT           NOT NULL,
+    PRIMARY KEY (product_id),
+    KEY idx_product_ratings_average_rating (average_rating)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS helpful_votes;
+DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS questions;
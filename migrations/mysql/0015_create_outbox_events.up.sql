@@ -0,0 +1,10 @@
+-- Events waiting to be published, recorded with the change they describe
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id         UUID          NOT NULL,
+    type       VARCHAR(100)  NOT NULL,
+    subject    VARCHAR(100)  NOT NULL,
+    payload    JSON          NOT NULL,
+    created_at DATETIME(6)   NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_outbox_events_created_at (created_at)
+);
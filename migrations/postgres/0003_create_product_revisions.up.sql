@@ -0,0 +1,14 @@
+-- Append only history of every product mutation
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id         UUID         NOT NULL,
+    product_id UUID         NOT NULL,
+    revision   BIGINT       NOT NULL,
+    actor      VARCHAR(255) NOT NULL,
+    source     VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL,
+    snapshot   JSON         NOT NULL,
+    changes    JSON         NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_product_revisions_product_revision UNIQUE (product_id, revision)
+);
+CREATE INDEX IF NOT EXISTS idx_product_revisions_product_created_at ON product_revisions (product_id, created_at);
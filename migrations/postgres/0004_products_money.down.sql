@@ -0,0 +1,22 @@
+-- Currencies are lost, amounts are converted back assuming two decimals
+ALTER TABLE products
+    ADD COLUMN price               NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN shipping_base_price NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price               = price_amount / 100.0,
+    shipping_base_price = shipping_base_price_amount / 100.0;
+
+ALTER TABLE products
+    DROP COLUMN price_currency,
+    DROP COLUMN price_amount,
+    DROP COLUMN shipping_base_price_currency,
+    DROP COLUMN shipping_base_price_amount;
+
+UPDATE product_revisions
+SET snapshot = (snapshot::jsonb
+    || jsonb_build_object(
+        'price', (snapshot->'price'->>'amount')::numeric / 100,
+        'shipping_base_price', (snapshot->'shipping_base_price'->>'amount')::numeric / 100
+    ))::json
+WHERE json_typeof(snapshot->'price') = 'object';
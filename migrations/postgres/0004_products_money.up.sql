@@ -0,0 +1,26 @@
+-- Prices move from decimals to integer minor units with a currency. Existing
+-- prices had no currency and are assumed to be USD; update price_currency and
+-- shipping_base_price_currency after migrating when the catalogue used another
+-- currency with two decimals.
+ALTER TABLE products
+    ADD COLUMN price_currency               CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN price_amount                 BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN shipping_base_price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN shipping_base_price_amount   BIGINT  NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price_amount               = ROUND(price * 100),
+    shipping_base_price_amount = ROUND(shipping_base_price * 100);
+
+ALTER TABLE products
+    DROP COLUMN price,
+    DROP COLUMN shipping_base_price;
+
+-- Keep the recorded snapshots readable by converting their prices as well
+UPDATE product_revisions
+SET snapshot = (snapshot::jsonb
+    || jsonb_build_object(
+        'price', jsonb_build_object('currency', 'USD', 'amount', ROUND((snapshot->>'price')::numeric * 100)::bigint),
+        'shipping_base_price', jsonb_build_object('currency', 'USD', 'amount', ROUND((snapshot->>'shipping_base_price')::numeric * 100)::bigint)
+    ))::json
+WHERE json_typeof(snapshot->'price') = 'number';
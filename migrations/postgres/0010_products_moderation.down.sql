@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN moderation_hits;
+ALTER TABLE products DROP COLUMN moderation_score;
@@ -0,0 +1,17 @@
+-- Images uploaded for products, the same content at most once per product
+CREATE TABLE IF NOT EXISTS product_images (
+    id           UUID         NOT NULL,
+    product_id   UUID         NOT NULL,
+    position     INTEGER      NOT NULL,
+    is_primary   BOOLEAN      NOT NULL,
+    alt_text     VARCHAR(250) NOT NULL,
+    content_type VARCHAR(50)  NOT NULL,
+    size         BIGINT       NOT NULL,
+    width        INTEGER      NOT NULL,
+    height       INTEGER      NOT NULL,
+    checksum     CHAR(64)     NOT NULL,
+    storage_key  VARCHAR(255) NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_checksum ON product_images (product_id, checksum);
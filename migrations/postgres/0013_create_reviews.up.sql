@@ -0,0 +1,32 @@
+-- Customer reviews of products, at most one per customer and product
+CREATE TABLE IF NOT EXISTS reviews (
+    id                UUID          NOT NULL,
+    product_id        UUID          NOT NULL,
+    customer_id       UUID          NOT NULL,
+    author_name       VARCHAR(100)  NOT NULL,
+    rating            INTEGER       NOT NULL,
+    title             VARCHAR(200)  NOT NULL,
+    body              TEXT          NOT NULL,
+    verified_purchase BOOLEAN       NOT NULL,
+    status            VARCHAR(20)   NOT NULL,
+    status_reason     VARCHAR(500)  NOT NULL,
+    created_at        TIMESTAMPTZ   NOT NULL,
+    updated_at        TIMESTAMPTZ   NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_product_customer ON reviews (product_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_product_status_created_at ON reviews (product_id, status, created_at);
+
+-- Ratings of the published reviews, kept per product for listings
+CREATE TABLE IF NOT EXISTS product_ratings (
+    product_id     UUID          NOT NULL,
+    review_count   INTEGER       NOT NULL,
+    average_rating NUMERIC(3, 2) NOT NULL,
+    one_star       INTEGER       NOT NULL,
+    two_stars      INTEGER       NOT NULL,
+    three_stars    INTEGER       NOT NULL,
+    four_stars     INTEGER       NOT NULL,
+    five_stars     INTEGER       NOT NULL,
+    PRIMARY KEY (product_id)
+);
+CREATE INDEX IF NOT EXISTS idx_product_ratings_average_rating ON product_ratings (average_rating);
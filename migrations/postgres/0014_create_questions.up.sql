@@ -0,0 +1,41 @@
+-- Questions of customers about products and their answers, by the seller
+-- or other customers
+CREATE TABLE IF NOT EXISTS questions (
+    id            UUID          NOT NULL,
+    product_id    UUID          NOT NULL,
+    customer_id   UUID          NOT NULL,
+    author_name   VARCHAR(100)  NOT NULL,
+    body          VARCHAR(1000) NOT NULL,
+    status        VARCHAR(20)   NOT NULL,
+    status_reason VARCHAR(500)  NOT NULL,
+    upvotes       INTEGER       NOT NULL,
+    created_at    TIMESTAMPTZ   NOT NULL,
+    updated_at    TIMESTAMPTZ   NOT NULL,
+    notified_at   TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_questions_product_status ON questions (product_id, status);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id            UUID          NOT NULL,
+    question_id   UUID          NOT NULL,
+    author_id     UUID          NOT NULL,
+    author_name   VARCHAR(100)  NOT NULL,
+    author_role   VARCHAR(20)   NOT NULL,
+    body          VARCHAR(2000) NOT NULL,
+    status        VARCHAR(20)   NOT NULL,
+    status_reason VARCHAR(500)  NOT NULL,
+    upvotes       INTEGER       NOT NULL,
+    created_at    TIMESTAMPTZ   NOT NULL,
+    updated_at    TIMESTAMPTZ   NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_answers_question ON answers (question_id);
+
+-- One upvote per customer and question or answer
+CREATE TABLE IF NOT EXISTS helpful_votes (
+    entry_id      UUID          NOT NULL,
+    customer_id   UUID          NOT NULL,
+    created_at    TIMESTAMPTZ   NOT NULL,
+    PRIMARY KEY (entry_id, customer_id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS products (
+    id                      TEXT           NOT NULL,
+    name                    VARCHAR(255)   NOT NULL,
+    quantity                INTEGER        NOT NULL,
+    type                    VARCHAR(20)    NOT NULL,
+    category                VARCHAR(100)   NOT NULL,
+    image_urls              TEXT,
+    price                   NUMERIC(10, 2) NOT NULL,
+    width                   NUMERIC(5, 2),
+    height                  NUMERIC(5, 2),
+    weight                  NUMERIC(5, 2),
+    shipping_base_price     NUMERIC(10, 2) NOT NULL,
+    base_delivery_timelines INTEGER        NOT NULL,
+    seller_id               TEXT           NOT NULL,
+    PRIMARY KEY (id)
+);
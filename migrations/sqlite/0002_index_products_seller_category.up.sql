@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_products_seller_id ON products (seller_id);
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
@@ -0,0 +1,14 @@
+-- Append only history of every product mutation
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id         TEXT         NOT NULL,
+    product_id TEXT         NOT NULL,
+    revision   INTEGER      NOT NULL,
+    actor      VARCHAR(255) NOT NULL,
+    source     VARCHAR(100) NOT NULL,
+    created_at DATETIME     NOT NULL,
+    snapshot   TEXT         NOT NULL,
+    changes    TEXT         NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (product_id, revision)
+);
+CREATE INDEX IF NOT EXISTS idx_product_revisions_product_created_at ON product_revisions (product_id, created_at);
@@ -0,0 +1,18 @@
+-- Currencies are lost, amounts are converted back assuming two decimals
+ALTER TABLE products ADD COLUMN price NUMERIC(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN shipping_base_price NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price               = price_amount / 100.0,
+    shipping_base_price = shipping_base_price_amount / 100.0;
+
+ALTER TABLE products DROP COLUMN price_currency;
+ALTER TABLE products DROP COLUMN price_amount;
+ALTER TABLE products DROP COLUMN shipping_base_price_currency;
+ALTER TABLE products DROP COLUMN shipping_base_price_amount;
+
+UPDATE product_revisions
+SET snapshot = json_set(snapshot,
+    '$.price', json_extract(snapshot, '$.price.amount') / 100.0,
+    '$.shipping_base_price', json_extract(snapshot, '$.shipping_base_price.amount') / 100.0)
+WHERE json_type(snapshot, '$.price') = 'object';
@@ -0,0 +1,22 @@
+-- Prices move from decimals to integer minor units with a currency. Existing
+-- prices had no currency and are assumed to be USD; update price_currency and
+-- shipping_base_price_currency after migrating when the catalogue used another
+-- currency with two decimals.
+ALTER TABLE products ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE products ADD COLUMN price_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN shipping_base_price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE products ADD COLUMN shipping_base_price_amount BIGINT NOT NULL DEFAULT 0;
+
+UPDATE products
+SET price_amount               = CAST(ROUND(price * 100) AS INTEGER),
+    shipping_base_price_amount = CAST(ROUND(shipping_base_price * 100) AS INTEGER);
+
+ALTER TABLE products DROP COLUMN price;
+ALTER TABLE products DROP COLUMN shipping_base_price;
+
+-- Keep the recorded snapshots readable by converting their prices as well
+UPDATE product_revisions
+SET snapshot = json_set(snapshot,
+    '$.price', json_object('currency', 'USD', 'amount', CAST(ROUND(json_extract(snapshot, '$.price') * 100) AS INTEGER)),
+    '$.shipping_base_price', json_object('currency', 'USD', 'amount', CAST(ROUND(json_extract(snapshot, '$.shipping_base_price') * 100) AS INTEGER)))
+WHERE json_type(snapshot, '$.price') IN ('integer', 'real');
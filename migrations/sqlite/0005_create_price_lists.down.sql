@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fx_rates;
+DROP TABLE IF EXISTS product_prices;
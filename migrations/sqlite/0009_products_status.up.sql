@@ -0,0 +1,4 @@
+-- Products listed before the approval workflow stay live
+ALTER TABLE products ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE products ADD COLUMN status_reason VARCHAR(500) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_products_status ON products (status);
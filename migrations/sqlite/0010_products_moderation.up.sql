@@ -0,0 +1,4 @@
+-- Outcome of the moderation rules, hits are a JSON encoded list
+ALTER TABLE products ADD COLUMN moderation_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN moderation_hits TEXT;
+UPDATE products SET moderation_hits = '';
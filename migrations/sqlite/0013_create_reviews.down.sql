@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_ratings;
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,41 @@
+-- Questions of customers about products and their answers, by the seller
+-- or other customers
+CREATE TABLE IF NOT EXISTS questions (
+    id            TEXT          NOT NULL,
+    product_id    TEXT          NOT NULL,
+    customer_id   TEXT          NOT NULL,
+    author_name   VARCHAR(100)  NOT NULL,
+    body          VARCHAR(1000) NOT NULL,
+    status        VARCHAR(20)   NOT NULL,
+    status_reason VARCHAR(500)  NOT NULL,
+    upvotes       INTEGER       NOT NULL,
+    created_at    DATETIME      NOT NULL,
+    updated_at    DATETIME      NOT NULL,
+    notified_at   DATETIME,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_questions_product_status ON questions (product_id, status);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id            TEXT          NOT NULL,
+    question_id   TEXT          NOT NULL,
+    author_id     TEXT          NOT NULL,
+    author_name   VARCHAR(100)  NOT NULL,
+    author_role   VARCHAR(20)   NOT NULL,
+    body          VARCHAR(2000) NOT NULL,
+    status        VARCHAR(20)   NOT NULL,
+    status_reason VARCHAR(500)  NOT NULL,
+    upvotes       INTEGER       NOT NULL,
+    created_at    DATETIME      NOT NULL,
+    updated_at    DATETIME      NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_answers_question ON answers (question_id);
+
+-- One upvote per customer and question or answer
+CREATE TABLE IF NOT EXISTS helpful_votes (
+    entry_id      TEXT          NOT NULL,
+    customer_id   TEXT          NOT NULL,
+    created_at    DATETIME      NOT NULL,
+    PRIMARY KEY (entry_id, customer_id)
+);
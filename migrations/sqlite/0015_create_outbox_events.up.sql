@@ -0,0 +1,10 @@
+-- Events waiting to be published, recorded with the change they describe
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id         TEXT          NOT NULL,
+    type       VARCHAR(100)  NOT NULL,
+    subject    VARCHAR(100)  NOT NULL,
+    payload    TEXT          NOT NULL,
+    created_at DATETIME      NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
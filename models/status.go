@@ -0,0 +1,51 @@
+package models
+
+// Lifecycle statuses of a product. Only active products are listed publicly.
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusActive        = "active"
+	ProductStatusRejected      = "rejected"
+	ProductStatusSuspended     = "suspended"
+	ProductStatusDiscontinued  = "discontinued"
+)
+
+// MaxStatusReasonLength is the size, in characters, of the status_reason
+// column.
+const MaxStatusReasonLength = 500
+
+// productTransitions lists the statuses each status may move to.
+// Discontinued products never come back.
+var productTransitions = map[string][]string{
+	ProductStatusDraft:         {ProductStatusPendingReview, ProductStatusDiscontinued},
+	ProductStatusPendingReview: {ProductStatusActive, ProductStatusRejected, ProductStatusDiscontinued},
+	ProductStatusActive:        {ProductStatusPendingReview, ProductStatusSuspended, ProductStatusDiscontinued},
+	ProductStatusRejected:      {ProductStatusPendingReview, ProductStatusDiscontinued},
+	ProductStatusSuspended:     {ProductStatusActive, ProductStatusDiscontinued},
+}
+
+// CanTransition reports whether a product may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, status := range productTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Moderation statuses of a review. Only published reviews are listed publicly
+// and rated.
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusPublished = "published"
+	ReviewStatusRejected  = "rejected"
+)
+
+// Moderation statuses of questions and their answers. Only published ones are
+// listed publicly, answered and upvoted.
+const (
+	QuestionStatusPending   = "pending"
+	QuestionStatusPublished = "published"
+	QuestionStatusRejected  = "rejected"
+)
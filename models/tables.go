@@ -1,24 +1,56 @@
 package models
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
 	"gorm.io/gorm"
 )
 
+// The schema is owned by the SQL migrations in migrations/, one set per
+// dialect. The tags below stay dialect neutral so the models work on MySQL,
+// PostgreSQL and SQLite alike.
+
+// Product is a listing of a seller. Its Price and ShippingBasePrice are in
+// the same currency.
 type Product struct {
-	ID                    uuid.UUID `gorm:"type:uuid;primaryKey;" json:"product_id"`
-	Name                  string    `gorm:"type:varchar(255);not null" json:"name"`
-	Quantity              int32     `gorm:"not null" json:"quantity"`
-	Type                  string    `gorm:"type:varchar(20);not null" json:"type"`
-	Category              string    `gorm:"type:varchar(100);not null" json:"category"`
-	ImageUrls             string    `gorm:"type:json" json:"image_urls"`
-	Price                 float64   `gorm:"type:decimal(10,2);not null" json:"price"`
-	Width                 float64   `gorm:"type:decimal(5,2)" json:"width"`
-	Height                float64   `gorm:"type:decimal(5,2)" json:"height"`
-	Weight                float64   `gorm:"type:decimal(5,2)" json:"weight"`
-	ShippingBasePrice     float64   `gorm:"type:decimal(10,2);not null" json:"shipping_base_price"`
-	BaseDeliveryTimelines int32     `gorm:"not null" json:"base_delivery_timelines"`
-	SellerId              uuid.UUID `gorm:"type:uuid;not null" json:"seller_id"`
+	ID                    uuid.UUID   `gorm:"primaryKey" json:"product_id"`
+	Name                  string      `gorm:"size:255;not null" json:"name"`
+	Quantity              int32       `gorm:"not null" json:"quantity"`
+	Type                  string      `gorm:"size:20;not null" json:"type"`
+	Category              string      `gorm:"size:100;not null" json:"category"`
+	ImageUrls             string      `json:"image_urls"`
+	Price                 money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	Width                 float64     `gorm:"precision:5;scale:2" json:"width"`
+	Height                float64     `gorm:"precision:5;scale:2" json:"height"`
+	Depth                 float64     `gorm:"precision:5;scale:2" json:"depth"`
+	Weight                float64     `gorm:"precision:5;scale:2" json:"weight"`
+	ShippingBasePrice     money.Money `gorm:"embedded;embeddedPrefix:shipping_base_price_" json:"shipping_base_price"`
+	BaseDeliveryTimelines int32       `gorm:"not null" json:"base_delivery_timelines"`
+	SellerId              uuid.UUID   `gorm:"not null" json:"seller_id"`
+	// Status is the lifecycle status, changed through its transitions only.
+	Status       string `gorm:"size:20;not null;index" json:"status"`
+	StatusReason string `gorm:"size:500;not null" json:"status_reason"`
+	// ModerationScore and ModerationHits hold the outcome of the moderation
+	// rules at the last create, update or submission. ModerationHits is a
+	// JSON encoded list of RuleHit.
+	ModerationScore int32  `gorm:"not null" json:"moderation_score"`
+	ModerationHits  string `json:"moderation_hits"`
+	// Sale is set when a price schedule changed Price on read. It is never
+	// stored nor cached.
+	Sale *Sale `gorm:"-" json:"-"`
+	// PriceTiers are loaded on request only, ordered by MinQuantity.
+	PriceTiers []PriceTier `gorm:"-" json:"-"`
+}
+
+// RuleHit is a match of a moderation rule in a field of a product.
+type RuleHit struct {
+	Rule  string `json:"rule"`
+	Field string `json:"field"`
+	Match string `json:"match"`
+	Score int    `json:"score"`
 }
 
 func (product *Product) BeforeCreate(tx *gorm.DB) (err error) {
@@ -26,3 +58,293 @@ func (product *Product) BeforeCreate(tx *gorm.DB) (err error) {
 	product.ID = uuid.New()
 	return nil
 }
+
+// ProductRevision records one mutation of a product. Revisions are numbered
+// per product from 1 and never modified.
+type ProductRevision struct {
+	ID        uuid.UUID `gorm:"primaryKey" json:"id"`
+	ProductId uuid.UUID `gorm:"not null" json:"product_id"`
+	Revision  int64     `gorm:"not null" json:"revision"`
+	// Actor is the caller which made the change, see security.Subject.
+	Actor string `gorm:"size:255;not null" json:"actor"`
+	// Source is the full name of the RPC which made the change.
+	Source    string    `gorm:"size:100;not null" json:"source"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	// Snapshot is the JSON encoded product after the change.
+	Snapshot string `gorm:"not null" json:"snapshot"`
+	// Changes is the JSON encoded list of FieldChange the mutation made.
+	Changes string `gorm:"not null" json:"changes"`
+}
+
+// FieldChange is the change of a single product field. Values are JSON
+// encoded, Old is empty when the product was created.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new"`
+}
+
+// Product decodes the snapshot of the revision.
+func (revision ProductRevision) Product() (Product, error) {
+	var product Product
+	err := json.Unmarshal([]byte(revision.Snapshot), &product)
+	return product, err
+}
+
+// FieldChanges decodes the changes of the revision.
+func (revision ProductRevision) FieldChanges() ([]FieldChange, error) {
+	var changes []FieldChange
+	err := json.Unmarshal([]byte(revision.Changes), &changes)
+	return changes, err
+}
+
+func (revision *ProductRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	revision.ID = uuid.New()
+	return nil
+}
+
+// ProductPrice is an explicit price of a product in a currency other than its
+// own. It takes precedence over converting the product price.
+type ProductPrice struct {
+	ProductId uuid.UUID `gorm:"primaryKey" json:"product_id"`
+	Currency  string    `gorm:"primaryKey;size:3" json:"currency"`
+	Amount    int64     `gorm:"not null" json:"amount"`
+}
+
+// Money returns the price as an amount of its currency.
+func (price ProductPrice) Money() money.Money {
+	return money.Money{Currency: price.Currency, Amount: price.Amount}
+}
+
+// FxRate states that one unit of BaseCurrency is worth Rate units of
+// QuoteCurrency. Rate is a decimal kept as text so it is never rounded.
+type FxRate struct {
+	BaseCurrency  string    `gorm:"primaryKey;size:3" json:"base_currency"`
+	QuoteCurrency string    `gorm:"primaryKey;size:3" json:"quote_currency"`
+	Rate          string    `gorm:"size:32;not null" json:"rate"`
+	UpdatedAt     time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// PriceSchedule changes the price of the products it targets between StartAt
+// and EndAt. It is applied when products are read, never written to them.
+type PriceSchedule struct {
+	ID   uuid.UUID `gorm:"primaryKey" json:"id"`
+	Name string    `gorm:"size:255;not null" json:"name"`
+	// TargetType is product, category or seller, matched against TargetId.
+	TargetType string `gorm:"size:20;not null" json:"target_type"`
+	TargetId   string `gorm:"size:100;not null" json:"target_id"`
+	// Kind is percent_off, amount_off or fixed_price.
+	Kind string `gorm:"size:20;not null" json:"kind"`
+	// PercentOff is in basis points, 2000 is 20% off.
+	PercentOff int64 `gorm:"not null" json:"percent_off"`
+	// Amount is the discount of amount_off or the price of fixed_price.
+	Amount    money.Money `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	StartAt   time.Time   `gorm:"not null" json:"start_at"`
+	EndAt     time.Time   `gorm:"not null" json:"end_at"`
+	Priority  int32       `gorm:"not null" json:"priority"`
+	CreatedBy string      `gorm:"size:255;not null" json:"created_by"`
+	CreatedAt time.Time   `gorm:"not null" json:"created_at"`
+}
+
+func (schedule *PriceSchedule) BeforeCreate(tx *gorm.DB) (err error) {
+	schedule.ID = uuid.New()
+	return nil
+}
+
+// Sale describes the price schedule applied to a product when it was read.
+type Sale struct {
+	ScheduleId    uuid.UUID
+	OriginalPrice money.Money
+	EndsAt        time.Time
+}
+
+// PriceTier is the unit price of a product from MinQuantity units on, for a
+// customer group. The empty group holds the tiers of every other customer.
+type PriceTier struct {
+	ProductId     uuid.UUID   `gorm:"primaryKey" json:"product_id"`
+	CustomerGroup string      `gorm:"primaryKey;size:50" json:"customer_group"`
+	MinQuantity   int32       `gorm:"primaryKey" json:"min_quantity"`
+	UnitPrice     money.Money `gorm:"embedded;embeddedPrefix:unit_price_" json:"unit_price"`
+}
+
+// ProductImage is an image uploaded for a product, stored in a blob store
+// under StorageKey. Images are ordered by Position from 0, and exactly one
+// image of a product is its primary image.
+type ProductImage struct {
+	ID          uuid.UUID `gorm:"primaryKey" json:"id"`
+	ProductId   uuid.UUID `gorm:"not null;uniqueIndex:idx_product_images_checksum" json:"product_id"`
+	Position    int32     `gorm:"not null" json:"position"`
+	IsPrimary   bool      `gorm:"not null" json:"is_primary"`
+	AltText     string    `gorm:"size:250;not null" json:"alt_text"`
+	ContentType string    `gorm:"size:50;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Width       int32     `gorm:"not null" json:"width"`
+	Height      int32     `gorm:"not null" json:"height"`
+	// Checksum is the hex encoded SHA-256 of the content.
+	Checksum   string    `gorm:"size:64;not null;uniqueIndex:idx_product_images_checksum" json:"checksum"`
+	StorageKey string    `gorm:"size:255;not null" json:"storage_key"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+	// Orientation is the EXIF orientation of the stored content, applied to
+	// its renditions.
+	Orientation int32 `gorm:"not null" json:"orientation"`
+	// RenditionsStatus is pending until the renditions are rendered, then
+	// ready, or failed when the image cannot be rendered.
+	RenditionsStatus string `gorm:"size:20;not null;index" json:"renditions_status"`
+	// Renditions are loaded with the image, ordered by width.
+	Renditions []ProductImageRendition `gorm:"-" json:"-"`
+}
+
+// BeforeCreate keeps an id assigned beforehand, as the storage key of the
+// image is derived from it.
+func (image *ProductImage) BeforeCreate(tx *gorm.DB) (err error) {
+	if image.ID == uuid.Nil {
+		image.ID = uuid.New()
+	}
+	return nil
+}
+
+// Statuses of the renditions of an image
+const (
+	RenditionsPending = "pending"
+	RenditionsReady   = "ready"
+	RenditionsFailed  = "failed"
+)
+
+// ProductImageRendition is a resized copy of a product image, stored in a
+// blob store under StorageKey.
+type ProductImageRendition struct {
+	ImageId     uuid.UUID `gorm:"primaryKey" json:"image_id"`
+	Name        string    `gorm:"primaryKey;size:20" json:"name"`
+	ContentType string    `gorm:"size:50;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Width       int32     `gorm:"not null" json:"width"`
+	Height      int32     `gorm:"not null" json:"height"`
+	StorageKey  string    `gorm:"size:255;not null" json:"storage_key"`
+}
+
+// Review is the review of a product by a customer, who reviews a product at
+// most once. Only published reviews count towards the ProductRating.
+type Review struct {
+	ID         uuid.UUID `gorm:"primaryKey" json:"review_id"`
+	ProductId  uuid.UUID `gorm:"not null;uniqueIndex:idx_reviews_product_customer" json:"product_id"`
+	CustomerId uuid.UUID `gorm:"not null;uniqueIndex:idx_reviews_product_customer" json:"customer_id"`
+	// AuthorName is the name the review is shown under.
+	AuthorName string `gorm:"size:100;not null" json:"author_name"`
+	Rating     int32  `gorm:"not null" json:"rating"`
+	Title      string `gorm:"size:200;not null" json:"title"`
+	Body       string `gorm:"not null" json:"body"`
+	// VerifiedPurchase is only set by the callers allowed to vouch for a
+	// purchase, such as the order service.
+	VerifiedPurchase bool      `gorm:"not null" json:"verified_purchase"`
+	Status           string    `gorm:"size:20;not null" json:"status"`
+	StatusReason     string    `gorm:"size:500;not null" json:"status_reason"`
+	CreatedAt        time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"not null" json:"updated_at"`
+}
+
+func (review *Review) BeforeCreate(tx *gorm.DB) (err error) {
+	review.ID = uuid.New()
+	return nil
+}
+
+// ProductRating aggregates the published reviews of a product. It is
+// updated whenever one of them is published or withdrawn, so listings can
+// sort on it without reading the reviews.
+type ProductRating struct {
+	ProductId     uuid.UUID `gorm:"primaryKey" json:"product_id"`
+	ReviewCount   int32     `gorm:"not null" json:"review_count"`
+	AverageRating float64   `gorm:"precision:3;scale:2;not null;index" json:"average_rating"`
+	// Histogram of the ratings, the number of reviews rating the product
+	// with 1 to 5 stars.
+	OneStar    int32 `gorm:"not null" json:"one_star"`
+	TwoStars   int32 `gorm:"not null" json:"two_stars"`
+	ThreeStars int32 `gorm:"not null" json:"three_stars"`
+	FourStars  int32 `gorm:"not null" json:"four_stars"`
+	FiveStars  int32 `gorm:"not null" json:"five_stars"`
+}
+
+// Histogram returns the number of reviews per rating, from 1 to 5 stars.
+func (rating ProductRating) Histogram() []int32 {
+	return []int32{rating.OneStar, rating.TwoStars, rating.ThreeStars, rating.FourStars, rating.FiveStars}
+}
+
+// Question is asked by a customer about a product, before buying it.
+type Question struct {
+	ID         uuid.UUID `gorm:"primaryKey" json:"question_id"`
+	ProductId  uuid.UUID `gorm:"not null;index" json:"product_id"`
+	CustomerId uuid.UUID `gorm:"not null" json:"customer_id"`
+	AuthorName string    `gorm:"size:100;not null" json:"author_name"`
+	Body       string    `gorm:"size:1000;not null" json:"body"`
+	Status     string    `gorm:"size:20;not null" json:"status"`
+	// StatusReason is the reason of the last moderation decision.
+	StatusReason string `gorm:"size:500;not null" json:"status_reason"`
+	// Upvotes counts the customers who found the question helpful.
+	Upvotes   int32     `gorm:"not null" json:"upvotes"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	// NotifiedAt is when the question was first published and notified to
+	// the seller, nil until then.
+	NotifiedAt *time.Time `json:"notified_at"`
+	// Answers are loaded on listing only, the most helpful first.
+	Answers []Answer `gorm:"-" json:"-"`
+}
+
+func (question *Question) BeforeCreate(tx *gorm.DB) (err error) {
+	question.ID = uuid.New()
+	return nil
+}
+
+// Answer authors are either the seller of the product or other customers
+const (
+	AnswerBySeller   = "seller"
+	AnswerByCustomer = "customer"
+)
+
+// Answer replies to a question, by the seller of the product or by another
+// customer.
+type Answer struct {
+	ID         uuid.UUID `gorm:"primaryKey" json:"answer_id"`
+	QuestionId uuid.UUID `gorm:"not null;index" json:"question_id"`
+	AuthorId   uuid.UUID `gorm:"not null" json:"author_id"`
+	AuthorName string    `gorm:"size:100;not null" json:"author_name"`
+	// AuthorRole is AnswerBySeller or AnswerByCustomer, set from the seller
+	// of the product for answers sent by a seller agent rather than claimed
+	// by the caller.
+	AuthorRole   string    `gorm:"size:20;not null" json:"author_role"`
+	Body         string    `gorm:"size:2000;not null" json:"body"`
+	Status       string    `gorm:"size:20;not null" json:"status"`
+	StatusReason string    `gorm:"size:500;not null" json:"status_reason"`
+	Upvotes      int32     `gorm:"not null" json:"upvotes"`
+	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
+}
+
+func (answer *Answer) BeforeCreate(tx *gorm.DB) (err error) {
+	answer.ID = uuid.New()
+	return nil
+}
+
+// HelpfulVote records that a customer upvoted a question or an answer, so
+// each customer upvotes an entry once.
+type HelpfulVote struct {
+	EntryId    uuid.UUID `gorm:"primaryKey" json:"entry_id"`
+	CustomerId uuid.UUID `gorm:"primaryKey" json:"customer_id"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+}
+
+// OutboxEvent is an event recorded in the transaction of the change it
+// describes, until it is published and deleted.
+type OutboxEvent struct {
+	ID   uuid.UUID `gorm:"primaryKey" json:"event_id"`
+	Type string    `gorm:"size:100;not null" json:"type"`
+	// Subject identifies who the event is about, e.g. the seller to notify.
+	Subject string `gorm:"size:100;not null" json:"subject"`
+	// Payload is the JSON encoded event.
+	Payload   string    `gorm:"not null" json:"payload"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+func (event *OutboxEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	event.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// listedStatuses are the statuses of products still part of the catalog, the
+// ones duplicates are searched among.
+var listedStatuses = []string{
+	models.ProductStatusDraft,
+	models.ProductStatusPendingReview,
+	models.ProductStatusActive,
+	models.ProductStatusSuspended,
+}
+
+// GetSellerCategoryProducts lists the listed products of a seller in a
+// category, regardless of the case of the category.
+func GetSellerCategoryProducts(ctx context.Context, sellerId string, category string, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.GetSellerCategoryProducts")
+	defer span.End()
+
+	var products []models.Product
+	queryCtx, done := trackQuery(ctx, "get_seller_category_products")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("seller_id = ? AND LOWER(category) = ? AND status IN ?", sellerId, strings.ToLower(category), listedStatuses).
+		Find(&products).Error
+	done(err)
+	return products, err
+}
+
+// GetCatalog lists the listed products, only those of category unless it is
+// empty.
+func GetCatalog(ctx context.Context, category string, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.GetCatalog")
+	defer span.End()
+
+	var products []models.Product
+	queryCtx, done := trackQuery(ctx, "get_catalog")
+	query := storage.Reader(ctx).Instance.WithContext(queryCtx).Where("status IN ?", listedStatuses)
+	if category != "" {
+		query = query.Where("LOWER(category) = ?", strings.ToLower(category))
+	}
+	err := query.Order("id").Find(&products).Error
+	done(err)
+	return products, err
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/events"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// recordEvent adds an event to the outbox in the transaction of the change it
+// describes, so it is published if and only if the change is committed.
+func recordEvent(tx *database.RelationalDB, eventType string, subject string, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Insert(&models.OutboxEvent{
+		Type:      eventType,
+		Subject:   subject,
+		Payload:   string(encoded),
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// GetOutboxEvents lists up to limit events waiting to be published, oldest
+// first.
+func GetOutboxEvents(ctx context.Context, limit int, storage *database.RelationalDatabase) ([]events.Event, error) {
+	ctx, span := tracer.Start(ctx, "service.GetOutboxEvents")
+	defer span.End()
+
+	var outbox []models.OutboxEvent
+	queryCtx, done := trackQuery(ctx, "get_outbox_events")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&outbox).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]events.Event, len(outbox))
+	for i, event := range outbox {
+		result[i] = events.Event{
+			ID:        event.ID.String(),
+			Type:      event.Type,
+			Subject:   event.Subject,
+			Payload:   []byte(event.Payload),
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// DeleteOutboxEvents removes published events from the outbox.
+func DeleteOutboxEvents(ctx context.Context, ids []string, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.DeleteOutboxEvents")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "delete_outbox_events")
+	err := storage.DB().WithContext(queryCtx).
+		Where("id IN ?", ids).
+		Delete(&models.OutboxEvent{}).Error
+	done(err)
+	return err
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImageNotFound  = errors.New("image not found")
+	ErrDuplicateImage = errors.New("image already uploaded for the product")
+	ErrTooManyImages  = errors.New("too many images")
+	ErrImageOrder     = errors.New("image order must list every image of the product once")
+)
+
+// AddProductImage records an image stored for a product, last in the order.
+// The first image of a product, or one flagged IsPrimary, becomes its primary
+// image.
+func AddProductImage(ctx context.Context, image models.ProductImage, maxImages int, storage *database.RelationalDatabase) (models.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "service.AddProductImage")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "add_product_image")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var existing []models.ProductImage
+		if err := tx.Instance.Where("product_id = ?", image.ProductId).Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) >= maxImages {
+			return fmt.Errorf("%w, a product has at most %d images", ErrTooManyImages, maxImages)
+		}
+		position := int32(0)
+		for _, other := range existing {
+			if other.Checksum == image.Checksum {
+				return fmt.Errorf("%w as image %s", ErrDuplicateImage, other.ID)
+			}
+			if other.Position >= position {
+				position = other.Position + 1
+			}
+		}
+
+		image.Position = position
+		image.IsPrimary = image.IsPrimary || len(existing) == 0
+		image.RenditionsStatus = models.RenditionsPending
+		image.CreatedAt = time.Now().UTC()
+		if image.IsPrimary {
+			if err := clearPrimaryImage(tx, image.ProductId.String()); err != nil {
+				return err
+			}
+		}
+		return tx.Insert(&image)
+	})
+	done(err)
+	return image, err
+}
+
+// GetProductImages lists the images of the products with their renditions,
+// by product id and in order.
+func GetProductImages(ctx context.Context, productIds []string, storage *database.RelationalDatabase) (map[string][]models.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProductImages")
+	defer span.End()
+
+	result := map[string][]models.ProductImage{}
+	if len(productIds) == 0 {
+		return result, nil
+	}
+	var images []models.ProductImage
+	queryCtx, done := trackQuery(ctx, "get_product_images")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id IN ?", productIds).
+		Order("position").
+		Find(&images).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadRenditions(ctx, images, storage); err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		id := image.ProductId.String()
+		result[id] = append(result[id], image)
+	}
+	return result, nil
+}
+
+// GetPendingImages lists up to limit images still waiting for their
+// renditions, oldest first.
+func GetPendingImages(ctx context.Context, limit int, storage *database.RelationalDatabase) ([]models.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "service.GetPendingImages")
+	defer span.End()
+
+	var images []models.ProductImage
+	queryCtx, done := trackQuery(ctx, "get_pending_images")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("renditions_status = ?", models.RenditionsPending).
+		Order("created_at").
+		Limit(limit).
+		Find(&images).Error
+	done(err)
+	return images, err
+}
+
+// SaveImageRenditions replaces the renditions of an image and sets their
+// status.
+func SaveImageRenditions(ctx context.Context, imageId string, renditions []models.ProductImageRendition, status string, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.SaveImageRenditions")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "save_image_renditions")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		result := tx.Instance.Model(&models.ProductImage{}).Where("id = ?", imageId).Update("renditions_status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrImageNotFound
+		}
+		if err := tx.Instance.Where("image_id = ?", imageId).Delete(&models.ProductImageRendition{}).Error; err != nil {
+			return err
+		}
+		if len(renditions) == 0 {
+			return nil
+		}
+		return tx.Instance.Create(&renditions).Error
+	})
+	done(err)
+	return err
+}
+
+// UpdateProductImage replaces the alt text of an image, and makes it the
+// primary image of its product when primary is set.
+func UpdateProductImage(ctx context.Context, productId string, imageId string, altText string, primary bool, storage *database.RelationalDatabase) (models.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "service.UpdateProductImage")
+	defer span.End()
+
+	var image models.ProductImage
+	queryCtx, done := trackQuery(ctx, "update_product_image")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ? AND product_id = ?", imageId, productId).First(&image).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrImageNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if primary && !image.IsPrimary {
+			if err := clearPrimaryImage(tx, productId); err != nil {
+				return err
+			}
+			image.IsPrimary = true
+		}
+		image.AltText = altText
+		return tx.Update(&image)
+	})
+	done(err)
+	return image, err
+}
+
+// ReorderProductImages moves the images of a product to the order of
+// imageIds, which must list each of them once.
+func ReorderProductImages(ctx context.Context, productId string, imageIds []string, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.ReorderProductImages")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "reorder_product_images")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var images []models.ProductImage
+		if err := tx.Instance.Where("product_id = ?", productId).Find(&images).Error; err != nil {
+			return err
+		}
+		positions := make(map[string]int32, len(imageIds))
+		for i, id := range imageIds {
+			positions[id] = int32(i)
+		}
+		if len(images) != len(imageIds) || len(positions) != len(imageIds) {
+			return ErrImageOrder
+		}
+		for _, image := range images {
+			position, ok := positions[image.ID.String()]
+			if !ok {
+				return ErrImageOrder
+			}
+			err := tx.Instance.Model(&models.ProductImage{}).Where("id = ?", image.ID).Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	done(err)
+	return err
+}
+
+// DeleteProductImage removes an image of a product and returns it with its
+// renditions, so their content can be deleted. The following images move up,
+// and the first remaining image becomes the primary image when the removed
+// one was.
+func DeleteProductImage(ctx context.Context, productId string, imageId string, storage *database.RelationalDatabase) (models.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "service.DeleteProductImage")
+	defer span.End()
+
+	var image models.ProductImage
+	queryCtx, done := trackQuery(ctx, "delete_product_image")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ? AND product_id = ?", imageId, productId).First(&image).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrImageNotFound
+		}
+		if err != nil {
+			return err
+		}
+		err = tx.Instance.Where("image_id = ?", image.ID).Find(&image.Renditions).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Instance.Where("image_id = ?", image.ID).Delete(&models.ProductImageRendition{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Instance.Delete(&image).Error; err != nil {
+			return err
+		}
+		err = tx.Instance.Model(&models.ProductImage{}).
+			Where("product_id = ? AND position > ?", productId, image.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil || !image.IsPrimary {
+			return err
+		}
+		return tx.Instance.Model(&models.ProductImage{}).
+			Where("product_id = ? AND position = 0", productId).
+			Update("is_primary", true).Error
+	})
+	done(err)
+	return image, err
+}
+
+// loadRenditions sets the renditions of each image, smallest first.
+func loadRenditions(ctx context.Context, images []models.ProductImage, storage *database.RelationalDatabase) error {
+	if len(images) == 0 {
+		return nil
+	}
+	ids := make([]string, len(images))
+	for i, image := range images {
+		ids[i] = image.ID.String()
+	}
+	var renditions []models.ProductImageRendition
+	queryCtx, done := trackQuery(ctx, "get_image_renditions")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("image_id IN ?", ids).
+		Order("width, height, name").
+		Find(&renditions).Error
+	done(err)
+	if err != nil {
+		return err
+	}
+	byImage := map[uuid.UUID][]models.ProductImageRendition{}
+	for _, rendition := range renditions {
+		byImage[rendition.ImageId] = append(byImage[rendition.ImageId], rendition)
+	}
+	for i := range images {
+		images[i].Renditions = byImage[images[i].ID]
+	}
+	return nil
+}
+
+func clearPrimaryImage(tx *database.RelationalDB, productId string) error {
+	return tx.Instance.Model(&models.ProductImage{}).
+		Where("product_id = ? AND is_primary = ?", productId, true).
+		Update("is_primary", false).Error
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/tittuvarghese/ss-go-product-service/service")
+
+// trackQuery starts a client span and a latency measurement for a database
+// operation. The returned function ends both and must be called with the
+// outcome of the operation.
+func trackQuery(ctx context.Context, operation string) (context.Context, func(err error)) {
+	ctx, span := tracer.Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation.name", operation)),
+	)
+	observe := metrics.TrackQuery(operation)
+	return ctx, func(err error) {
+		observe(err)
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "database query failed", "operation", operation, "error", err)
+		}
+		endSpan(span, err)
+	}
+}
+
+// endSpan records the error, if any, on the span before ending it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/logging"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/core/pricing"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm/clause"
+)
+
+// SetProductPrices replaces the explicit prices of a product in other
+// currencies.
+func SetProductPrices(ctx context.Context, productId string, prices []models.ProductPrice, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.SetProductPrices")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "set_product_prices")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		if err := tx.Instance.Where("product_id = ?", productId).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		if len(prices) == 0 {
+			return nil
+		}
+		return tx.Insert(&prices)
+	})
+	done(err)
+	return err
+}
+
+// SetFxRates stores validated exchange rates, replacing the previous rate of
+// each currency pair.
+func SetFxRates(ctx context.Context, fxRates []pricing.Rate, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.SetFxRates")
+	defer span.End()
+
+	if len(fxRates) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rates := make([]models.FxRate, len(fxRates))
+	for i, rate := range fxRates {
+		rates[i] = models.FxRate{BaseCurrency: rate.Base, QuoteCurrency: rate.Quote, Rate: rate.Rate, UpdatedAt: now}
+	}
+
+	queryCtx, done := trackQuery(ctx, "set_fx_rates")
+	err := storage.DB().WithContext(queryCtx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&rates).Error
+	done(err)
+	return err
+}
+
+// GetFxRates lists every stored exchange rate.
+func GetFxRates(ctx context.Context, storage *database.RelationalDatabase) ([]models.FxRate, error) {
+	ctx, span := tracer.Start(ctx, "service.GetFxRates")
+	defer span.End()
+
+	var rates []models.FxRate
+	queryCtx, done := trackQuery(ctx, "get_fx_rates")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).Order("base_currency, quote_currency").Find(&rates).Error
+	done(err)
+	return rates, err
+}
+
+// LocalizePrices returns the products with their prices in currency. An
+// explicit price of the product in currency is used when set, otherwise the
+// price is converted with the stored exchange rates and rounded to the price
+// points of currency, like the unit prices of loaded price tiers. Shipping base
+// prices are always converted. A sale is localized from the list price: see
+// localizeSale. Products with an amount to convert without an exchange rate
+// keep their own currency, so one missing rate does not fail a listing.
+func LocalizePrices(ctx context.Context, products []models.Product, currency string, rounding pricing.Rounding, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.LocalizePrices")
+	defer span.End()
+
+	var ids []string
+	sources := map[string]bool{}
+	for _, product := range products {
+		if product.Price.Currency != currency {
+			ids = append(ids, product.ID.String())
+			sources[product.Price.Currency] = true
+		}
+	}
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	var overrides []models.ProductPrice
+	queryCtx, done := trackQuery(ctx, "get_product_prices")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id IN ? AND currency = ?", ids, currency).
+		Find(&overrides).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	explicit := make(map[string]money.Money, len(overrides))
+	for _, override := range overrides {
+		explicit[override.ProductId.String()] = override.Money()
+	}
+
+	rates, err := getRatesTo(ctx, currency, sources, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := make([]models.Product, len(products))
+	for i, product := range products {
+		localized[i] = product
+		if product.Price.Currency == currency {
+			continue
+		}
+		price, isExplicit := explicit[product.ID.String()]
+		result, err := localizeProduct(product, currency, price, isExplicit, rates[product.Price.Currency], rounding)
+		if errors.Is(err, pricing.ErrNoRate) {
+			logging.FromContext(ctx).WarnContext(ctx, "keeping the product currency", "product_id", product.ID.String(), "error", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		localized[i] = result
+	}
+	return localized, nil
+}
+
+// localizeProduct returns the product with its prices in currency, given its
+// explicit price in currency if isExplicit. The rate is only needed for the
+// amounts which are converted, and may be nil otherwise.
+func localizeProduct(product models.Product, currency string, price money.Money, isExplicit bool, rate *big.Rat, rounding pricing.Rounding) (models.Product, error) {
+	convert := func(amount money.Money) (money.Money, error) {
+		if amount.Amount == 0 {
+			return money.Money{Currency: currency}, nil
+		}
+		if rate == nil {
+			return money.Money{}, fmt.Errorf("%w from %s to %s", pricing.ErrNoRate, amount.Currency, currency)
+		}
+		return pricing.Convert(amount, currency, rate)
+	}
+
+	list, effective := product.Price, product.Price
+	if product.Sale != nil {
+		list = product.Sale.OriginalPrice
+	}
+	if !isExplicit {
+		converted, err := convert(list)
+		if err != nil {
+			return models.Product{}, err
+		}
+		price = rounding.Apply(converted)
+	}
+	product.Price = price
+	if product.Sale != nil {
+		salePrice, err := localizeSale(effective, list, price, isExplicit, convert, rounding)
+		if err != nil {
+			return models.Product{}, err
+		}
+		if salePrice != price {
+			sale := *product.Sale
+			sale.OriginalPrice = price
+			product.Sale, product.Price = &sale, salePrice
+		} else {
+			product.Sale = nil
+		}
+	}
+
+	var err error
+	if product.ShippingBasePrice, err = convert(product.ShippingBasePrice); err != nil {
+		return models.Product{}, err
+	}
+	if len(product.PriceTiers) > 0 {
+		tiers := make([]models.PriceTier, len(product.PriceTiers))
+		for j, tier := range product.PriceTiers {
+			price, err := convert(tier.UnitPrice)
+			if err != nil {
+				return models.Product{}, err
+			}
+			tier.UnitPrice = rounding.Apply(price)
+			tiers[j] = tier
+		}
+		product.PriceTiers = tiers
+	}
+	return product, nil
+}
+
+// localizeSale returns the sale price of the list price in the currency of
+// localized, the localized list price. A converted list price comes with the
+// sale price converted and rounded the same way, while an explicit list price
+// gets the same share of discount as the price in the currency of the product.
+func localizeSale(salePrice money.Money, list money.Money, localized money.Money, isExplicit bool, convert func(money.Money) (money.Money, error), rounding pricing.Rounding) (money.Money, error) {
+	if !isExplicit || list.Amount == 0 {
+		price, err := convert(salePrice)
+		if err != nil {
+			return money.Money{}, err
+		}
+		return rounding.Apply(price), nil
+	}
+	return pricing.Convert(localized, localized.Currency, big.NewRat(salePrice.Amount, list.Amount))
+}
+
+// getRatesTo finds the rate from each source currency to currency. A stored
+// rate in the opposite direction is inverted when no direct rate exists.
+func getRatesTo(ctx context.Context, currency string, sources map[string]bool, storage *database.RelationalDatabase) (map[string]*big.Rat, error) {
+	codes := make([]string, 0, len(sources))
+	for code := range sources {
+		codes = append(codes, code)
+	}
+
+	var stored []models.FxRate
+	queryCtx, done := trackQuery(ctx, "get_fx_rates_to")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("(base_currency IN ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency IN ?)", codes, currency, currency, codes).
+		Find(&stored).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]*big.Rat, len(codes))
+	for _, fx := range stored {
+		rate, err := pricing.ParseRate(fx.Rate)
+		if err != nil {
+			return nil, err
+		}
+		if fx.QuoteCurrency == currency {
+			rates[fx.BaseCurrency] = rate
+		} else if _, ok := rates[fx.QuoteCurrency]; !ok {
+			rates[fx.QuoteCurrency] = rate.Inv(rate)
+		}
+	}
+	return rates, nil
+}
+
+// CreatePriceSchedule stores a validated price schedule.
+func CreatePriceSchedule(ctx context.Context, schedule models.PriceSchedule, storage *database.RelationalDatabase) (models.PriceSchedule, error) {
+	ctx, span := tracer.Start(ctx, "service.CreatePriceSchedule")
+	defer span.End()
+
+	schedule.StartAt = schedule.StartAt.UTC()
+	schedule.EndAt = schedule.EndAt.UTC()
+	schedule.CreatedAt = time.Now().UTC()
+
+	_, done := trackQuery(ctx, "create_price_schedule")
+	err := storage.Instance.Insert(&schedule)
+	done(err)
+	return schedule, err
+}
+
+// DeletePriceSchedule removes a price schedule, ending it right away when it
+// is running. It reports whether the schedule existed.
+func DeletePriceSchedule(ctx context.Context, scheduleId string, storage *database.RelationalDatabase) (bool, error) {
+	ctx, span := tracer.Start(ctx, "service.DeletePriceSchedule")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "delete_price_schedule")
+	res := storage.DB().WithContext(queryCtx).Where("id = ?", scheduleId).Delete(&models.PriceSchedule{})
+	done(res.Error)
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetPriceSchedules lists the price schedules ending after the given time,
+// ordered by start.
+func GetPriceSchedules(ctx context.Context, endingAfter time.Time, storage *database.RelationalDatabase) ([]models.PriceSchedule, error) {
+	ctx, span := tracer.Start(ctx, "service.GetPriceSchedules")
+	defer span.End()
+
+	var schedules []models.PriceSchedule
+	queryCtx, done := trackQuery(ctx, "get_price_schedules")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("end_at > ?", endingAfter.UTC()).
+		Order("start_at, id").
+		Find(&schedules).Error
+	done(err)
+	return schedules, err
+}
+
+// SetPriceTiers replaces the tiers of a customer group of a product.
+func SetPriceTiers(ctx context.Context, productId string, customerGroup string, tiers []models.PriceTier, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.SetPriceTiers")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "set_price_tiers")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("product_id = ? AND customer_group = ?", productId, customerGroup).Delete(&models.PriceTier{}).Error
+		if err != nil || len(tiers) == 0 {
+			return err
+		}
+		return tx.Insert(&tiers)
+	})
+	done(err)
+	return err
+}
+
+// clearStaleTiers deletes the price tiers left in the previous currency of a
+// product whose currency changed from before to after, as tiers must be in
+// the currency of the product.
+func clearStaleTiers(tx *database.RelationalDB, before models.Product, after models.Product) error {
+	if before.Price.Currency == after.Price.Currency {
+		return nil
+	}
+	return tx.Instance.Where("product_id = ? AND unit_price_currency <> ?", after.ID, after.Price.Currency).Delete(&models.PriceTier{}).Error
+}
+
+// LoadPriceTiers sets the tiers of a customer group on each product. Products
+// without tiers for the group get the tiers of the default, empty group.
+func LoadPriceTiers(ctx context.Context, products []models.Product, customerGroup string, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.LoadPriceTiers")
+	defer span.End()
+
+	if len(products) == 0 {
+		return products, nil
+	}
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID.String()
+	}
+
+	var tiers []models.PriceTier
+	queryCtx, done := trackQuery(ctx, "get_price_tiers")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id IN ? AND customer_group IN ?", ids, []string{customerGroup, ""}).
+		Order("min_quantity").
+		Find(&tiers).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string][]models.PriceTier{}
+	defaults := map[string][]models.PriceTier{}
+	for _, tier := range tiers {
+		id := tier.ProductId.String()
+		if tier.CustomerGroup == customerGroup {
+			groups[id] = append(groups[id], tier)
+		} else {
+			defaults[id] = append(defaults[id], tier)
+		}
+	}
+
+	loaded := make([]models.Product, len(products))
+	for i, product := range products {
+		loaded[i] = product
+		id := product.ID.String()
+		if tiers, ok := groups[id]; ok {
+			loaded[i].PriceTiers = tiers
+		} else {
+			loaded[i].PriceTiers = defaults[id]
+		}
+	}
+	return loaded, nil
+}
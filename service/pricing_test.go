@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/migrate"
+	"github.com/tittuvarghese/ss-go-product-service/core/money"
+	"github.com/tittuvarghese/ss-go-product-service/migrations"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+)
+
+// openDatabase opens a migrated SQLite database in a temporary directory.
+func openDatabase(t *testing.T) *database.RelationalDatabase {
+	t.Helper()
+	storage, err := database.NewRelationalDatabase("sqlite://" + filepath.Join(t.TempDir(), "products.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	sqlDB, err := storage.SqlDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, migrations.Files, migrate.Options{Dialect: storage.Dialect})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestCurrencyChangeClearsStaleTiers(t *testing.T) {
+	ctx := context.Background()
+	storage := openDatabase(t)
+	audit := Audit{Actor: "seller", Source: "test"}
+
+	usd := func(amount int64) money.Money { return money.Money{Currency: "USD", Amount: amount} }
+	product, err := CreateProduct(ctx, models.Product{
+		Name:              "kettle",
+		Quantity:          10,
+		Category:          "kitchen",
+		Price:             usd(1000),
+		ShippingBasePrice: usd(100),
+		SellerId:          uuid.New(),
+		Status:            models.ProductStatusActive,
+		ImageUrls:         "[]",
+	}, audit, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range []string{"", "wholesale"} {
+		tiers := []models.PriceTier{{ProductId: product.ID, CustomerGroup: group, MinQuantity: 10, UnitPrice: usd(900)}}
+		if err := SetPriceTiers(ctx, product.ID.String(), group, tiers, storage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	countTiers := func() int64 {
+		var count int64
+		if err := storage.DB().Model(&models.PriceTier{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	// Tiers are kept while the currency does not change
+	product.Price = usd(1200)
+	if err := UpdateProduct(ctx, product, audit, storage); err != nil {
+		t.Fatal(err)
+	}
+	if count := countTiers(); count != 2 {
+		t.Errorf("%d tiers after a price change, want 2", count)
+	}
+
+	product.Price = money.Money{Currency: "EUR", Amount: 1100}
+	product.ShippingBasePrice = money.Money{Currency: "EUR", Amount: 100}
+	if err := UpdateProduct(ctx, product, audit, storage); err != nil {
+		t.Fatal(err)
+	}
+	if count := countTiers(); count != 0 {
+		t.Errorf("%d tiers after a currency change, want 0", count)
+	}
+
+	// Reverting to a revision in another currency clears the tiers too
+	tiers := []models.PriceTier{{ProductId: product.ID, MinQuantity: 5, UnitPrice: money.Money{Currency: "EUR", Amount: 1000}}}
+	if err := SetPriceTiers(ctx, product.ID.String(), "", tiers, storage); err != nil {
+		t.Fatal(err)
+	}
+	keep := func(*models.Product) error { return nil }
+	if _, err := RevertProduct(ctx, product.ID.String(), 1, keep, audit, storage); err != nil {
+		t.Fatal(err)
+	}
+	if count := countTiers(); count != 0 {
+		t.Errorf("%d tiers after reverting to another currency, want 0", count)
+	}
+}
@@ -1,32 +1,56 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/metrics"
 	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm"
 )
 
-func CreateProduct(product models.Product, storage *database.RelationalDatabase) error {
-	err := storage.Instance.Insert(&product)
-	if err != nil {
+// CreateProduct stores a new product and the first revision of its history.
+func CreateProduct(ctx context.Context, product models.Product, audit Audit, storage *database.RelationalDatabase) (models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateProduct")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "create_product")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		if err := tx.Insert(&product); err != nil {
+			return err
+		}
+		_, err := recordRevision(tx, nil, product, audit)
 		return err
+	})
+	done(err)
+	if err != nil {
+		return models.Product{}, err
 	}
-	return nil
+	return product, nil
 }
 
-func GetProduct(productId string, storage *database.RelationalDatabase) ([]models.Product, error) {
+func GetProduct(ctx context.Context, productId string, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProduct")
+	defer span.End()
+
 	var product []models.Product
 	condition := map[string]interface{}{"id": productId}
 
 	// Query the database with the given condition
-	res, err := storage.Instance.QueryByCondition(&product, condition)
+	_, done := trackQuery(ctx, "get_product")
+	res, err := storage.Reader(ctx).QueryByCondition(&product, condition)
+	done(err)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []models.Product{}, ErrProductNotFound
+	}
 	if err != nil {
 		return []models.Product{}, err
 	}
 
 	// Check if the result contains any products
 	if len(res) <= 0 {
-		return []models.Product{}, fmt.Errorf("product not found")
+		return []models.Product{}, ErrProductNotFound
 	}
 
 	foundProduct, ok := res[0].(*[]models.Product) // Type assertion to pointer of models.Product
@@ -38,13 +62,27 @@ func GetProduct(productId string, storage *database.RelationalDatabase) ([]model
 	return *foundProduct, nil
 }
 
-func GetProducts(storage *database.RelationalDatabase) (*[]models.Product, error) {
+// GetProducts lists the active products, in the order of sort or in storage
+// order when it is empty.
+func GetProducts(ctx context.Context, sort string, storage *database.RelationalDatabase) (*[]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProducts")
+	defer span.End()
+
+	if sort != "" {
+		return getRatedProducts(ctx, sort, storage)
+	}
+
 	var products []models.Product
-	condition := map[string]interface{}{}
+	condition := map[string]interface{}{"status": models.ProductStatusActive}
 
 	// Pass a slice of Product to QueryByCondition
-	res, err := storage.Instance.QueryByCondition(&products, condition)
+	_, done := trackQuery(ctx, "get_products")
+	res, err := storage.Reader(ctx).QueryByCondition(&products, condition)
+	done(err)
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("products not found")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,10 +100,78 @@ func GetProducts(storage *database.RelationalDatabase) (*[]models.Product, error
 	return result, nil
 }
 
-func UpdateProduct(product models.Product, storage *database.RelationalDatabase) error {
-	err := storage.Instance.Update(&product)
+// GetProductsByIds loads the given products. Unknown ids are skipped.
+func GetProductsByIds(ctx context.Context, productIds []string, storage *database.RelationalDatabase) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProductsByIds")
+	defer span.End()
+
+	var products []models.Product
+	condition := map[string]interface{}{"id": productIds}
+
+	_, done := trackQuery(ctx, "get_products_by_ids")
+	res, err := storage.Reader(ctx).QueryByCondition(&products, condition)
+	done(err)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []models.Product{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return []models.Product{}, nil
+	}
+	result, ok := res[0].(*[]models.Product)
+	if !ok {
+		return nil, fmt.Errorf("type assertion failed")
+	}
+	return *result, nil
+}
+
+// UpdateProduct saves the product and records the fields it changed in the
+// product history.
+func UpdateProduct(ctx context.Context, product models.Product, audit Audit, storage *database.RelationalDatabase) error {
+	ctx, span := tracer.Start(ctx, "service.UpdateProduct")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "update_product")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var current models.Product
+		if err := lockProduct(tx, product.ID, &current); err != nil {
+			return err
+		}
+		if err := tx.Update(&product); err != nil {
+			return err
+		}
+		if err := clearStaleTiers(tx, current, product); err != nil {
+			return err
+		}
+		_, err := recordRevision(tx, &current, product, audit)
+		return err
+	})
+	done(err)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// GetInventoryStats counts the active products and those which ran out of stock.
+func GetInventoryStats(ctx context.Context, storage *database.RelationalDatabase) (metrics.InventoryStats, error) {
+	var stats metrics.InventoryStats
+
+	queryCtx, done := trackQuery(ctx, "count_products")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).Model(&models.Product{}).Where("status = ?", models.ProductStatusActive).Count(&stats.Products).Error
+	done(err)
+	if err != nil {
+		return stats, err
+	}
+
+	queryCtx, done = trackQuery(ctx, "count_out_of_stock_products")
+	err = storage.Reader(ctx).Instance.WithContext(queryCtx).Model(&models.Product{}).Where("status = ? AND quantity <= ?", models.ProductStatusActive, 0).Count(&stats.OutOfStock).Error
+	done(err)
+	if err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
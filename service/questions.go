@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/core/events"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrAlreadyUpvoted   = errors.New("customer already upvoted")
+	ErrOwnEntry         = errors.New("authors cannot upvote their own questions and answers")
+)
+
+// QuestionCursor is the position of the last question of a page, in the
+// order of GetQuestions.
+type QuestionCursor struct {
+	Upvotes    int32
+	CreatedAt  time.Time
+	QuestionId string
+}
+
+// AskQuestion stores the question of a customer. A published question is
+// notified to the seller of the product.
+func AskQuestion(ctx context.Context, question models.Question, storage *database.RelationalDatabase) (models.Question, error) {
+	ctx, span := tracer.Start(ctx, "service.AskQuestion")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "ask_question")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		question.CreatedAt = time.Now().UTC()
+		question.UpdatedAt = question.CreatedAt
+		notify := question.Status == models.QuestionStatusPublished
+		if notify {
+			question.NotifiedAt = &question.CreatedAt
+		}
+		if err := tx.Insert(&question); err != nil {
+			return err
+		}
+		if !notify {
+			return nil
+		}
+		return questionAsked(tx, question)
+	})
+	done(err)
+	return question, err
+}
+
+// GetQuestion reads a question.
+func GetQuestion(ctx context.Context, questionId string, storage *database.RelationalDatabase) (models.Question, error) {
+	ctx, span := tracer.Start(ctx, "service.GetQuestion")
+	defer span.End()
+
+	var question models.Question
+	queryCtx, done := trackQuery(ctx, "get_question")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).Where("id = ?", questionId).First(&question).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = ErrQuestionNotFound
+	}
+	done(err)
+	return question, err
+}
+
+// AnswerQuestion stores an answer to a published question.
+func AnswerQuestion(ctx context.Context, answer models.Answer, storage *database.RelationalDatabase) (models.Answer, error) {
+	ctx, span := tracer.Start(ctx, "service.AnswerQuestion")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "answer_question")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var questions int64
+		err := tx.Instance.Model(&models.Question{}).
+			Where("id = ? AND status = ?", answer.QuestionId, models.QuestionStatusPublished).
+			Count(&questions).Error
+		if err != nil {
+			return err
+		}
+		if questions == 0 {
+			return ErrQuestionNotFound
+		}
+		answer.CreatedAt = time.Now().UTC()
+		answer.UpdatedAt = answer.CreatedAt
+		return tx.Insert(&answer)
+	})
+	done(err)
+	return answer, err
+}
+
+// GetQuestions lists a page of the questions of a product with the given
+// status, the most upvoted first, then the newest. Each question comes with
+// its answers of answerStatus, the most upvoted first, then the oldest. A nil
+// cursor starts from the first question.
+func GetQuestions(ctx context.Context, productId string, status string, answerStatus string, limit int, cursor *QuestionCursor, storage *database.RelationalDatabase) ([]models.Question, error) {
+	ctx, span := tracer.Start(ctx, "service.GetQuestions")
+	defer span.End()
+
+	var questions []models.Question
+	queryCtx, done := trackQuery(ctx, "get_questions")
+	query := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id = ? AND status = ?", productId, status)
+	if cursor != nil {
+		query = query.Where("upvotes < ? OR (upvotes = ? AND (created_at < ? OR (created_at = ? AND id < ?)))",
+			cursor.Upvotes, cursor.Upvotes, cursor.CreatedAt, cursor.CreatedAt, cursor.QuestionId)
+	}
+	err := query.Order("upvotes DESC, created_at DESC, id DESC").Limit(limit).Find(&questions).Error
+	done(err)
+	if err != nil || len(questions) == 0 {
+		return questions, err
+	}
+
+	ids := make([]uuid.UUID, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+	var answers []models.Answer
+	queryCtx, done = trackQuery(ctx, "get_answers")
+	err = storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("question_id IN ? AND status = ?", ids, answerStatus).
+		Order("upvotes DESC, created_at, id").
+		Find(&answers).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	byQuestion := map[uuid.UUID][]models.Answer{}
+	for _, answer := range answers {
+		byQuestion[answer.QuestionId] = append(byQuestion[answer.QuestionId], answer)
+	}
+	for i := range questions {
+		questions[i].Answers = byQuestion[questions[i].ID]
+	}
+	return questions, nil
+}
+
+// UpvoteQuestion records that a customer found a published question helpful.
+func UpvoteQuestion(ctx context.Context, questionId string, customerId uuid.UUID, storage *database.RelationalDatabase) (int32, error) {
+	ctx, span := tracer.Start(ctx, "service.UpvoteQuestion")
+	defer span.End()
+
+	var question models.Question
+	queryCtx, done := trackQuery(ctx, "upvote_question")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ? AND status = ?", questionId, models.QuestionStatusPublished).First(&question).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuestionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if question.CustomerId == customerId {
+			return ErrOwnEntry
+		}
+		return upvote(tx, &models.Question{}, question.ID, customerId)
+	})
+	done(err)
+	return question.Upvotes + 1, err
+}
+
+// UpvoteAnswer records that a customer found a published answer helpful.
+func UpvoteAnswer(ctx context.Context, answerId string, customerId uuid.UUID, storage *database.RelationalDatabase) (int32, error) {
+	ctx, span := tracer.Start(ctx, "service.UpvoteAnswer")
+	defer span.End()
+
+	var answer models.Answer
+	queryCtx, done := trackQuery(ctx, "upvote_answer")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ? AND status = ?", answerId, models.QuestionStatusPublished).First(&answer).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAnswerNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if answer.AuthorId == customerId {
+			return ErrOwnEntry
+		}
+		return upvote(tx, &models.Answer{}, answer.ID, customerId)
+	})
+	done(err)
+	return answer.Upvotes + 1, err
+}
+
+// ModerateQuestion moves a question to the given status. A question is
+// notified to the seller the first time it is published, whatever its status
+// was.
+func ModerateQuestion(ctx context.Context, questionId string, status string, reason string, storage *database.RelationalDatabase) (models.Question, error) {
+	ctx, span := tracer.Start(ctx, "service.ModerateQuestion")
+	defer span.End()
+
+	var question models.Question
+	queryCtx, done := trackQuery(ctx, "moderate_question")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ?", questionId).First(&question).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuestionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		notify := status == models.QuestionStatusPublished && question.NotifiedAt == nil
+		question.Status, question.StatusReason = status, reason
+		question.UpdatedAt = time.Now().UTC()
+		if notify {
+			question.NotifiedAt = &question.UpdatedAt
+		}
+		if err := tx.Update(&question); err != nil {
+			return err
+		}
+		if !notify {
+			return nil
+		}
+		return questionAsked(tx, question)
+	})
+	done(err)
+	return question, err
+}
+
+// ModerateAnswer moves an answer to the given status.
+func ModerateAnswer(ctx context.Context, answerId string, status string, reason string, storage *database.RelationalDatabase) (models.Answer, error) {
+	ctx, span := tracer.Start(ctx, "service.ModerateAnswer")
+	defer span.End()
+
+	var answer models.Answer
+	queryCtx, done := trackQuery(ctx, "moderate_answer")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ?", answerId).First(&answer).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAnswerNotFound
+		}
+		if err != nil {
+			return err
+		}
+		answer.Status, answer.StatusReason = status, reason
+		answer.UpdatedAt = time.Now().UTC()
+		return tx.Update(&answer)
+	})
+	done(err)
+	return answer, err
+}
+
+// upvote records the vote of a customer for a question or an answer and
+// counts it on the entry, a model of the table to update. The primary key of
+// the votes rejects a second vote of the customer with ErrAlreadyUpvoted.
+func upvote(tx *database.RelationalDB, entry interface{}, entryId uuid.UUID, customerId uuid.UUID) error {
+	vote := models.HelpfulVote{EntryId: entryId, CustomerId: customerId, CreatedAt: time.Now().UTC()}
+	err := tx.Insert(&vote)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrAlreadyUpvoted
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Instance.Model(entry).Where("id = ?", entryId).Update("upvotes", gorm.Expr("upvotes + 1")).Error
+}
+
+// questionAsked records the notification of a question to the seller of the
+// product.
+func questionAsked(tx *database.RelationalDB, question models.Question) error {
+	var product models.Product
+	if err := tx.Instance.Where("id = ?", question.ProductId).First(&product).Error; err != nil {
+		return err
+	}
+	return recordEvent(tx, events.TypeQuestionAsked, product.SellerId.String(), events.QuestionAsked{
+		QuestionId:  question.ID.String(),
+		ProductId:   product.ID.String(),
+		ProductName: product.Name,
+		SellerId:    product.SellerId.String(),
+		AuthorName:  question.AuthorName,
+		Body:        question.Body,
+		AskedAt:     question.CreatedAt,
+	})
+}
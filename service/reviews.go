@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sort orders of product listings, see GetProducts
+const (
+	SortByRating      = "rating"
+	SortByReviewCount = "review_count"
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrDuplicateReview = errors.New("customer already reviewed the product")
+)
+
+// ReviewCursor is the position of the last review of a page: the next page
+// lists the reviews created before it.
+type ReviewCursor struct {
+	CreatedAt time.Time
+	ReviewId  string
+}
+
+// ReviewFilter selects the reviews of a product to list. A zero Rating lists
+// every rating.
+type ReviewFilter struct {
+	Status string
+	Rating int32
+}
+
+// CreateReview stores the review of a customer, failing with
+// ErrDuplicateReview when the unique index finds that the customer already
+// reviewed the product. The rating of the product is updated right away when
+// the review is published.
+func CreateReview(ctx context.Context, review models.Review, storage *database.RelationalDatabase) (models.Review, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateReview")
+	defer span.End()
+
+	queryCtx, done := trackQuery(ctx, "create_review")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		review.CreatedAt = time.Now().UTC()
+		review.UpdatedAt = review.CreatedAt
+		err := tx.Insert(&review)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateReview
+		}
+		if err != nil {
+			return err
+		}
+		if review.Status != models.ReviewStatusPublished {
+			return nil
+		}
+		return countReview(tx, review, 1)
+	})
+	done(err)
+	return review, err
+}
+
+// GetReviews lists a page of the reviews of a product, newest first. A nil
+// cursor starts from the newest review.
+func GetReviews(ctx context.Context, productId string, filter ReviewFilter, limit int, cursor *ReviewCursor, storage *database.RelationalDatabase) ([]models.Review, error) {
+	ctx, span := tracer.Start(ctx, "service.GetReviews")
+	defer span.End()
+
+	var reviews []models.Review
+	queryCtx, done := trackQuery(ctx, "get_reviews")
+	query := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id = ? AND status = ?", productId, filter.Status)
+	if filter.Rating > 0 {
+		query = query.Where("rating = ?", filter.Rating)
+	}
+	if cursor != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ReviewId)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&reviews).Error
+	done(err)
+	return reviews, err
+}
+
+// ModerateReview moves a review to the given status, updating the rating of
+// its product when the review is published or withdrawn.
+func ModerateReview(ctx context.Context, reviewId string, status string, reason string, storage *database.RelationalDatabase) (models.Review, error) {
+	ctx, span := tracer.Start(ctx, "service.ModerateReview")
+	defer span.End()
+
+	var review models.Review
+	queryCtx, done := trackQuery(ctx, "moderate_review")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		err := tx.Instance.Where("id = ?", reviewId).First(&review).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReviewNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var delta int32
+		switch {
+		case review.Status != models.ReviewStatusPublished && status == models.ReviewStatusPublished:
+			delta = 1
+		case review.Status == models.ReviewStatusPublished && status != models.ReviewStatusPublished:
+			delta = -1
+		}
+		review.Status, review.StatusReason = status, reason
+		review.UpdatedAt = time.Now().UTC()
+		if err := tx.Update(&review); err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		return countReview(tx, review, delta)
+	})
+	done(err)
+	return review, err
+}
+
+// GetProductRatings returns the ratings of the products by product id.
+// Products without published reviews are missing.
+func GetProductRatings(ctx context.Context, productIds []string, storage *database.RelationalDatabase) (map[string]models.ProductRating, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProductRatings")
+	defer span.End()
+
+	result := map[string]models.ProductRating{}
+	if len(productIds) == 0 {
+		return result, nil
+	}
+	var ratings []models.ProductRating
+	queryCtx, done := trackQuery(ctx, "get_product_ratings")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id IN ? AND review_count > 0", productIds).
+		Find(&ratings).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	for _, rating := range ratings {
+		result[rating.ProductId.String()] = rating
+	}
+	return result, nil
+}
+
+// ratingColumns are the histogram columns of product_ratings by rating.
+var ratingColumns = map[int32]string{
+	1: "one_star",
+	2: "two_stars",
+	3: "three_stars",
+	4: "four_stars",
+	5: "five_stars",
+}
+
+// averageRating recomputes the average rating of a product_ratings row from
+// its histogram, rounded to 2 decimals.
+const averageRating = "CASE WHEN review_count > 0 THEN ROUND((one_star + 2 * two_stars + 3 * three_stars + 4 * four_stars + 5 * five_stars) * 1.0 / review_count, 2) ELSE 0 END"
+
+// countReview adds a published review to the rating of its product, or
+// removes a withdrawn one with a delta of -1. The counts are updated in
+// place rather than recomputed from the reviews, so concurrent changes to
+// the reviews of a product all add up.
+func countReview(tx *database.RelationalDB, review models.Review, delta int32) error {
+	column, ok := ratingColumns[review.Rating]
+	if !ok {
+		return fmt.Errorf("review %s rated %d", review.ID, review.Rating)
+	}
+	rating := models.ProductRating{ProductId: review.ProductId, ReviewCount: delta}
+	histogram := []*int32{&rating.OneStar, &rating.TwoStars, &rating.ThreeStars, &rating.FourStars, &rating.FiveStars}
+	*histogram[review.Rating-1] = delta
+	err := tx.Instance.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"review_count": gorm.Expr("product_ratings.review_count + ?", delta),
+			column:         gorm.Expr("product_ratings."+column+" + ?", delta),
+		}),
+	}).Create(&rating).Error
+	if err != nil {
+		return err
+	}
+	return tx.Instance.Model(&models.ProductRating{}).
+		Where("product_id = ?", review.ProductId).
+		Update("average_rating", gorm.Expr(averageRating)).Error
+}
+
+// getRatedProducts lists the active products by rating: the best average
+// rating first with SortByRating, the most reviews first with
+// SortByReviewCount. Products without reviews come last.
+func getRatedProducts(ctx context.Context, sort string, storage *database.RelationalDatabase) (*[]models.Product, error) {
+	order := "COALESCE(product_ratings.average_rating, 0) DESC, COALESCE(product_ratings.review_count, 0) DESC"
+	switch sort {
+	case SortByRating:
+	case SortByReviewCount:
+		order = "COALESCE(product_ratings.review_count, 0) DESC, COALESCE(product_ratings.average_rating, 0) DESC"
+	default:
+		return nil, fmt.Errorf("unknown sort order %q", sort)
+	}
+
+	var products []models.Product
+	queryCtx, done := trackQuery(ctx, "get_rated_products")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Joins("LEFT JOIN product_ratings ON product_ratings.product_id = products.id").
+		Where("products.status = ?", models.ProductStatusActive).
+		Order(order + ", products.id").
+		Find(&products).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, fmt.Errorf("products not found")
+	}
+	return &products, nil
+}
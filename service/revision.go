@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm/clause"
+)
+
+// Audit describes who made a product mutation and through which RPC.
+type Audit struct {
+	Actor  string
+	Source string
+}
+
+// lockProduct reads the product in a transaction, locking its row until the
+// transaction ends so concurrent mutations of one product run one after the
+// other, each from the state the previous one left. SQLite ignores the lock,
+// as it serializes writers anyway.
+func lockProduct(tx *database.RelationalDB, productId interface{}, product *models.Product) error {
+	return tx.Instance.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", productId).First(product).Error
+}
+
+// recordRevision appends a revision for the product after a mutation to the
+// history. before is nil when the product was just created. It must run in
+// the transaction of the mutation, after the product was read with
+// lockProduct, so concurrent mutations number their revisions one after the
+// other.
+func recordRevision(tx *database.RelationalDB, before *models.Product, after models.Product, audit Audit) (models.ProductRevision, error) {
+	var last int64
+	err := tx.Instance.Model(&models.ProductRevision{}).
+		Where("product_id = ?", after.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+	changes, err := diffProducts(before, after)
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+
+	revision := models.ProductRevision{
+		ProductId: after.ID,
+		Revision:  last + 1,
+		Actor:     audit.Actor,
+		Source:    audit.Source,
+		// Stored in UTC so timestamps compare correctly on every dialect
+		CreatedAt: time.Now().UTC(),
+		Snapshot:  string(snapshot),
+		Changes:   string(encodedChanges),
+	}
+	return revision, tx.Insert(&revision)
+}
+
+// diffProducts lists the fields which differ between before and after, in
+// the order of their JSON names. Every field is listed when before is nil.
+func diffProducts(before *models.Product, after models.Product) ([]models.FieldChange, error) {
+	afterFields, err := productFields(after)
+	if err != nil {
+		return nil, err
+	}
+	var beforeFields map[string]json.RawMessage
+	if before != nil {
+		if beforeFields, err = productFields(*before); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]string, 0, len(afterFields))
+	for field := range afterFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []models.FieldChange{}
+	for _, field := range fields {
+		change := models.FieldChange{Field: field, New: string(afterFields[field])}
+		if before != nil {
+			if string(beforeFields[field]) == change.New {
+				continue
+			}
+			change.Old = string(beforeFields[field])
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func productFields(product models.Product) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(product)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
+
+// GetProductHistory lists up to limit revisions of a product, newest first.
+// With beforeRevision set only older revisions are listed.
+func GetProductHistory(ctx context.Context, productId string, limit int, beforeRevision int64, storage *database.RelationalDatabase) ([]models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProductHistory")
+	defer span.End()
+
+	var revisions []models.ProductRevision
+	queryCtx, done := trackQuery(ctx, "get_product_history")
+	query := storage.Reader(ctx).Instance.WithContext(queryCtx).Where("product_id = ?", productId)
+	if beforeRevision > 0 {
+		query = query.Where("revision < ?", beforeRevision)
+	}
+	err := query.Order("revision DESC").Limit(limit).Find(&revisions).Error
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetProductRevisionAt returns the revision of a product in effect at the
+// given time, the last one recorded before it.
+func GetProductRevisionAt(ctx context.Context, productId string, at time.Time, storage *database.RelationalDatabase) (models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "service.GetProductRevisionAt")
+	defer span.End()
+
+	var revisions []models.ProductRevision
+	queryCtx, done := trackQuery(ctx, "get_product_revision_at")
+	err := storage.Reader(ctx).Instance.WithContext(queryCtx).
+		Where("product_id = ? AND created_at <= ?", productId, at.UTC()).
+		Order("revision DESC").
+		Limit(1).
+		Find(&revisions).Error
+	done(err)
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+	if len(revisions) == 0 {
+		return models.ProductRevision{}, fmt.Errorf("no revision of the product at %s", at.UTC().Format(time.RFC3339))
+	}
+	return revisions[0], nil
+}
+
+// RevertProduct restores the product to a prior revision, apart from its
+// lifecycle status, which only changes when moderate decides so on the
+// restored product. The restored state is recorded as a new revision, which
+// is returned.
+func RevertProduct(ctx context.Context, productId string, revision int64, moderate func(*models.Product) error, audit Audit, storage *database.RelationalDatabase) (models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "service.RevertProduct")
+	defer span.End()
+
+	var reverted models.ProductRevision
+	queryCtx, done := trackQuery(ctx, "revert_product")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var target []models.ProductRevision
+		err := tx.Instance.Where("product_id = ? AND revision = ?", productId, revision).Find(&target).Error
+		if err != nil {
+			return err
+		}
+		if len(target) == 0 {
+			return fmt.Errorf("revision %d of the product not found", revision)
+		}
+		restored, err := target[0].Product()
+		if err != nil {
+			return err
+		}
+
+		var current models.Product
+		if err := lockProduct(tx, productId, &current); err != nil {
+			return err
+		}
+		// The status only changes through its transitions
+		restored.Status, restored.StatusReason = current.Status, current.StatusReason
+		if err := moderate(&restored); err != nil {
+			return err
+		}
+		if err := tx.Update(&restored); err != nil {
+			return err
+		}
+		if err := clearStaleTiers(tx, current, restored); err != nil {
+			return err
+		}
+		reverted, err = recordRevision(tx, &current, restored, audit)
+		return err
+	})
+	done(err)
+	if err != nil {
+		return models.ProductRevision{}, err
+	}
+	return reverted, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tittuvarghese/ss-go-product-service/core/database"
+	"github.com/tittuvarghese/ss-go-product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// TransitionProduct moves the product to status and records the change in
+// the product history. The reason is kept until the next transition.
+func TransitionProduct(ctx context.Context, productId string, status string, reason string, audit Audit, storage *database.RelationalDatabase) (models.Product, error) {
+	ctx, span := tracer.Start(ctx, "service.TransitionProduct")
+	defer span.End()
+
+	var product models.Product
+	queryCtx, done := trackQuery(ctx, "transition_product")
+	err := storage.Instance.Transaction(queryCtx, func(tx *database.RelationalDB) error {
+		var current models.Product
+		err := lockProduct(tx, productId, &current)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !models.CanTransition(current.Status, status) {
+			return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, current.Status, status)
+		}
+
+		product = current
+		product.Status = status
+		product.StatusReason = reason
+		if err := tx.Update(&product); err != nil {
+			return err
+		}
+		_, err = recordRevision(tx, &current, product, audit)
+		return err
+	})
+	done(err)
+	if err != nil {
+		return models.Product{}, err
+	}
+	return product, nil
+}